@@ -0,0 +1,164 @@
+// package boosting implements AdaBoost ensemble of weak learners.
+//
+// Multiclass classification is supported by SAMME algorithm.
+// @see https://en.wikipedia.org/wiki/AdaBoost
+// @see https://hastie.su.domains/Papers/samme.pdf
+//
+package boosting
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/model"
+)
+
+var _ model.Model[float64] = (*AdaBoost[float64])(nil)
+
+// AdaBoost combines weak learners trained on reweighted samples round by round,
+// learners must honor model.Sample.Weight
+type AdaBoost[T constraints.Float] struct {
+	rounds     int
+	newLearner func() model.Model[T]
+	learners   []model.Model[T]
+	alphas     []T
+}
+
+// NewAdaBoost creates an AdaBoost model which trains at most rounds learners
+// created by newLearner, e.g. decision stumps:
+//
+//	boosting.NewAdaBoost(50, func() model.Model[T] {
+//		return cart.NewModel(dtree.PrePruning, dtree.WithMaxDepth[T](1))
+//	})
+func NewAdaBoost[T constraints.Float](rounds int, newLearner func() model.Model[T]) *AdaBoost[T] {
+	return &AdaBoost[T]{
+		rounds:     rounds,
+		newLearner: newLearner,
+	}
+}
+
+// Learners returns trained learners
+func (a *AdaBoost[T]) Learners() []model.Model[T] {
+	return a.learners
+}
+
+// EstimatorWeights returns weight of each trained learner
+func (a *AdaBoost[T]) EstimatorWeights() []T {
+	return a.alphas
+}
+
+// Train trains learners by SAMME: weights of samples misclassified by a
+// learner are boosted for the next round. Weights of samples passed to
+// learners are normalized to mean 1. Training stops after a perfect learner,
+// which is weighted more than all previous learners.
+func (a *AdaBoost[T]) Train(samples []model.Sample[T], tracker model.Tracker) {
+	a.learners = a.learners[:0]
+	a.alphas = a.alphas[:0]
+	if len(samples) == 0 {
+		return
+	}
+	var k = T(len(model.WeightedCounters(samples)))
+	var weighted = make([]model.Sample[T], len(samples))
+	copy(weighted, samples)
+	for i := range weighted {
+		weighted[i].Weight = model.WeightOf(samples[i])
+	}
+	normalize(weighted)
+
+	var misses = make([]bool, len(samples))
+	for round := 0; round < a.rounds; round++ {
+		var learner = a.newLearner()
+		learner.Train(weighted, nil)
+		var err, total T
+		for i, x := range weighted {
+			misses[i] = learner.Predict(x.Attributes) != x.Label
+			if misses[i] {
+				err += x.Weight
+			}
+			total += x.Weight
+		}
+		err /= total
+		if err < model.Epsilon {
+			// perfect learner, nothing left to boost, its weight is greater
+			// than sum of weights of previous learners so that it can't be
+			// outvoted by them
+			var alpha T = 1
+			for _, w := range a.alphas {
+				alpha += w
+			}
+			a.learners = append(a.learners, learner)
+			a.alphas = append(a.alphas, alpha)
+			break
+		}
+		if k < 2 || err >= 1-1/k {
+			// not better than random guessing
+			if len(a.learners) == 0 {
+				a.learners = append(a.learners, learner)
+				a.alphas = append(a.alphas, 1)
+			}
+			break
+		}
+		var alpha = T(math.Log(float64((1-err)/err))) + T(math.Log(float64(k-1)))
+		a.learners = append(a.learners, learner)
+		a.alphas = append(a.alphas, alpha)
+		for i := range weighted {
+			if misses[i] {
+				weighted[i].Weight *= T(math.Exp(float64(alpha)))
+			}
+		}
+		normalize(weighted)
+	}
+}
+
+// normalize scales weights of samples to mean 1
+func normalize[T constraints.Float](samples []model.Sample[T]) {
+	var sum T
+	for i := range samples {
+		sum += samples[i].Weight
+	}
+	var scale = T(len(samples)) / sum
+	for i := range samples {
+		samples[i].Weight *= scale
+	}
+}
+
+// Predict predicts label for x by weighted vote of all learners
+func (a *AdaBoost[T]) Predict(x tensor.Vector[T]) T {
+	var votes = make(map[T]T)
+	for i, learner := range a.learners {
+		votes[learner.Predict(x)] += a.alphas[i]
+	}
+	return vote(votes)
+}
+
+// StagedPredict returns predictions for x after each round, i.e. the i-th
+// prediction is voted by the first i+1 learners.
+func (a *AdaBoost[T]) StagedPredict(x tensor.Vector[T]) []T {
+	var predictions = make([]T, len(a.learners))
+	var votes = make(map[T]T)
+	for i, learner := range a.learners {
+		votes[learner.Predict(x)] += a.alphas[i]
+		predictions[i] = vote(votes)
+	}
+	return predictions
+}
+
+// vote returns the label which has maximum votes, the smaller label wins if tied
+func vote[T constraints.Float](votes map[T]T) T {
+	var labels = make([]T, 0, len(votes))
+	for label := range votes {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i] < labels[j]
+	})
+	var best T
+	for i, label := range labels {
+		if i == 0 || votes[label] > votes[best] {
+			best = label
+		}
+	}
+	return best
+}
@@ -0,0 +1,166 @@
+package boosting_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/doge/operator"
+	"github.com/gopherd/ml/boosting"
+	"github.com/gopherd/ml/dtree"
+	"github.com/gopherd/ml/dtree/cart"
+	"github.com/gopherd/ml/model"
+	"github.com/gopherd/ml/svm"
+)
+
+func newStump[T float32 | float64](attrs ...int) func() model.Model[T] {
+	return func() model.Model[T] {
+		return cart.NewModel(dtree.PrePruning,
+			dtree.WithMaxDepth[T](1),
+			dtree.WithContinuous[T](attrs...),
+		)
+	}
+}
+
+func errorRate[T float32 | float64](samples []model.Sample[T], predict func(tensor.Vector[T]) T) T {
+	var errors int
+	for _, x := range samples {
+		if predict(x.Attributes) != x.Label {
+			errors++
+		}
+	}
+	return T(errors) / T(len(samples))
+}
+
+func TestStumps(t *testing.T) {
+	type T = float64
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]model.Sample[T], 300)
+	for i := range samples {
+		x, y := r.Float64(), r.Float64()
+		samples[i].Attributes = tensor.Vec(x, y)
+		samples[i].Label = operator.If(x+y > 1, 1.0, -1.0)
+	}
+	var a = boosting.NewAdaBoost(50, newStump[T](0, 1))
+	a.Train(samples, nil)
+	if len(a.Learners()) != len(a.EstimatorWeights()) {
+		t.Fatalf("%d learners but %d weights", len(a.Learners()), len(a.EstimatorWeights()))
+	}
+	// staged errors
+	var errors = make([]int, len(a.Learners()))
+	for _, x := range samples {
+		for i, y := range a.StagedPredict(x.Attributes) {
+			if y != x.Label {
+				errors[i]++
+			}
+		}
+	}
+	var first, last = errors[0], errors[len(errors)-1]
+	t.Logf("rounds: %d, staged errors: %v", len(errors), errors)
+	if last >= first {
+		t.Fatalf("error not decreased: %d => %d", first, last)
+	}
+	if rate := errorRate(samples, a.Predict); rate > 0.1 {
+		t.Fatalf("error rate too large: %v", rate)
+	}
+}
+
+func TestSAMME(t *testing.T) {
+	type T = float64
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]model.Sample[T], 300)
+	for i := range samples {
+		x := r.Float64()
+		samples[i].Attributes = tensor.Vec(x)
+		samples[i].Label = T(int(x * 3))
+	}
+	var a = boosting.NewAdaBoost(20, newStump[T](0))
+	a.Train(samples, nil)
+	var rate = errorRate(samples, a.Predict)
+	t.Logf("rounds: %d, error rate: %v", len(a.Learners()), rate)
+	if rate > 0.05 {
+		t.Fatalf("error rate too large: %v", rate)
+	}
+}
+
+// threshold is a fixed learner predicting x[0] > value
+type threshold struct {
+	value float64
+}
+
+func (threshold) Train(samples []model.Sample[float64], tracker model.Tracker) {}
+
+func (t threshold) Predict(x tensor.Vector[float64]) float64 {
+	return operator.If(x[0] > t.value, 1.0, 0.0)
+}
+
+func TestPerfectLearner(t *testing.T) {
+	type T = float64
+	var samples = make([]model.Sample[T], 100)
+	for i := range samples {
+		x := (T(i) + 0.5) / T(len(samples))
+		samples[i].Attributes = tensor.Vec(x)
+		samples[i].Label = operator.If(x > 0.5, 1.0, 0.0)
+	}
+	// the perfect learner comes after two imperfect learners
+	var thresholds = []T{0.3, 0.7, 0.5}
+	var round int
+	var a = boosting.NewAdaBoost(10, func() model.Model[T] {
+		var learner = threshold{thresholds[round%len(thresholds)]}
+		round++
+		return learner
+	})
+	a.Train(samples, nil)
+	if rate := errorRate(samples, a.Predict); rate != 0 {
+		t.Fatalf("error rate: got %v, want 0", rate)
+	}
+	// previous learners are kept and outweighed by the perfect learner
+	var alphas = a.EstimatorWeights()
+	if len(a.Learners()) != 3 || len(alphas) != 3 {
+		t.Fatalf("learners: got %d learners and %d weights, want 3", len(a.Learners()), len(alphas))
+	}
+	if alphas[2] <= alphas[0]+alphas[1] {
+		t.Fatalf("weight of perfect learner %v not greater than sum of %v", alphas[2], alphas[:2])
+	}
+	for _, x := range samples {
+		var staged = a.StagedPredict(x.Attributes)
+		if len(staged) != 3 || staged[2] != x.Label {
+			t.Fatalf("staged predict %v: got %v, want last %v", x.Attributes, staged, x.Label)
+		}
+	}
+}
+
+func TestSVM(t *testing.T) {
+	type T = float64
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]model.Sample[T], 100)
+	for i := range samples {
+		x, y := r.Float64(), r.Float64()
+		samples[i].Attributes = tensor.Vec(x, y)
+		samples[i].Label = operator.If(x < y, 1.0, -1.0)
+	}
+	var a = boosting.NewAdaBoost(5, func() model.Model[T] {
+		return svm.NewClassifier[T](10, nil)
+	})
+	a.Train(samples, nil)
+	var rate = errorRate(samples, a.Predict)
+	t.Logf("rounds: %d, weights: %v, error rate: %v", len(a.Learners()), a.EstimatorWeights(), rate)
+	if rate > 0.05 {
+		t.Fatalf("error rate too large: %v", rate)
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	type T = float64
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]model.Sample[T], 200)
+	for i := range samples {
+		x, y := r.Float64(), r.Float64()
+		samples[i].Attributes = tensor.Vec(x, y)
+		samples[i].Label = operator.If(x+y > 1, 1.0, -1.0)
+	}
+	var a = boosting.NewAdaBoost(20, newStump[T](0, 1))
+	a.Train(samples, nil)
+
+	model.TestEncoding(a, func() *boosting.AdaBoost[T] { return boosting.NewAdaBoost(0, newStump[T]()) }, samples, t)
+}
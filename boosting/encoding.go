@@ -0,0 +1,127 @@
+package boosting
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/model"
+)
+
+// ErrLearnerNotEncodable is returned when learners can not be encoded or decoded
+var ErrLearnerNotEncodable = errors.New("boosting: learner not encodable")
+
+// Learners are arbitrary models, they are encoded by themselves and decoded
+// into learners created by newLearner. Learners should implement
+// json.Marshaler and json.Unmarshaler for JSON encoding,
+// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler for binary encoding.
+
+type jsonState[T constraints.Float] struct {
+	Alphas   []T               `json:"alphas"`
+	Learners []json.RawMessage `json:"learners"`
+}
+
+type binaryState[T constraints.Float] struct {
+	Alphas   []T
+	Learners [][]byte
+}
+
+func (a *AdaBoost[T]) newLearners(n int) ([]model.Model[T], error) {
+	if a.newLearner == nil {
+		return nil, ErrLearnerNotEncodable
+	}
+	var learners = make([]model.Model[T], n)
+	for i := range learners {
+		learners[i] = a.newLearner()
+	}
+	return learners, nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (a *AdaBoost[T]) MarshalJSON() ([]byte, error) {
+	var s = jsonState[T]{
+		Alphas:   a.alphas,
+		Learners: make([]json.RawMessage, len(a.learners)),
+	}
+	for i, learner := range a.learners {
+		m, ok := learner.(json.Marshaler)
+		if !ok {
+			return nil, ErrLearnerNotEncodable
+		}
+		data, err := m.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		s.Learners[i] = data
+	}
+	return model.EncodeJSON(s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *AdaBoost[T]) UnmarshalJSON(data []byte) error {
+	var s jsonState[T]
+	if err := model.DecodeJSON(data, &s); err != nil {
+		return err
+	}
+	learners, err := a.newLearners(len(s.Learners))
+	if err != nil {
+		return err
+	}
+	for i, learner := range learners {
+		u, ok := learner.(json.Unmarshaler)
+		if !ok {
+			return ErrLearnerNotEncodable
+		}
+		if err := u.UnmarshalJSON(s.Learners[i]); err != nil {
+			return err
+		}
+	}
+	a.alphas = s.Alphas
+	a.learners = learners
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (a *AdaBoost[T]) MarshalBinary() ([]byte, error) {
+	var s = binaryState[T]{
+		Alphas:   a.alphas,
+		Learners: make([][]byte, len(a.learners)),
+	}
+	for i, learner := range a.learners {
+		m, ok := learner.(encoding.BinaryMarshaler)
+		if !ok {
+			return nil, ErrLearnerNotEncodable
+		}
+		data, err := m.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		s.Learners[i] = data
+	}
+	return model.EncodeBinary(s)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (a *AdaBoost[T]) UnmarshalBinary(data []byte) error {
+	var s binaryState[T]
+	if err := model.DecodeBinary(data, &s); err != nil {
+		return err
+	}
+	learners, err := a.newLearners(len(s.Learners))
+	if err != nil {
+		return err
+	}
+	for i, learner := range learners {
+		u, ok := learner.(encoding.BinaryUnmarshaler)
+		if !ok {
+			return ErrLearnerNotEncodable
+		}
+		if err := u.UnmarshalBinary(s.Learners[i]); err != nil {
+			return err
+		}
+	}
+	a.alphas = s.Alphas
+	a.learners = learners
+	return nil
+}
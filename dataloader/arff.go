@@ -0,0 +1,415 @@
+package dataloader
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/model"
+)
+
+// arffAttribute is a declared attribute of ARFF
+type arffAttribute struct {
+	name    string
+	dict    *Dictionary // nil if numeric
+	nominal bool        // values of nominal attribute are declared
+}
+
+// unquoteARFF unquotes s quoted by single or double quotes
+func unquoteARFF(s string) (string, error) {
+	if len(s) == 0 || (s[0] != '\'' && s[0] != '"') {
+		return s, nil
+	}
+	var q = s[0]
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+			}
+			sb.WriteByte(s[i])
+		case q:
+			if i != len(s)-1 {
+				return "", fmt.Errorf("unexpected characters after quote in %s", s)
+			}
+			return sb.String(), nil
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return "", fmt.Errorf("unterminated quote in %s", s)
+}
+
+// quoteARFF quotes s if necessary
+func quoteARFF(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t,{}'\"%\\?") {
+		return s
+	}
+	var sb strings.Builder
+	sb.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\'' || s[i] == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(s[i])
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
+
+// splitARFF splits s by sep outside quotes, fields are trimmed but not unquoted
+func splitARFF(s string, sep byte) []string {
+	var fields []string
+	var start int
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		var c = s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == sep:
+			fields = append(fields, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(fields, strings.TrimSpace(s[start:]))
+}
+
+// closeBraceARFF returns index of '}' closing '{' at the beginning of s
+func closeBraceARFF(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		var c = s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+// nextTokenARFF returns the first token of s separated by spaces, quoted
+// token is unquoted.
+func nextTokenARFF(s string) (token, rest string, err error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return "", "", errors.New("missing token")
+	}
+	var end = len(s)
+	if s[0] == '\'' || s[0] == '"' {
+		end = -1
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == s[0] {
+				end = i + 1
+				break
+			}
+		}
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quote in %s", s)
+		}
+	} else if i := strings.IndexAny(s, " \t{"); i >= 0 {
+		end = i
+	}
+	token, err = unquoteARFF(s[:end])
+	return token, strings.TrimSpace(s[end:]), err
+}
+
+func parseAttributeARFF(s string) (arffAttribute, error) {
+	name, rest, err := nextTokenARFF(s)
+	if err != nil {
+		return arffAttribute{}, err
+	}
+	var attr = arffAttribute{name: name}
+	if strings.HasPrefix(rest, "{") {
+		if !strings.HasSuffix(rest, "}") {
+			return attr, fmt.Errorf("unterminated nominal values of %s", name)
+		}
+		attr.dict = new(Dictionary)
+		attr.nominal = true
+		for _, field := range splitARFF(rest[1:len(rest)-1], ',') {
+			value, err := unquoteARFF(field)
+			if err != nil {
+				return attr, err
+			}
+			attr.dict.add(value)
+		}
+		return attr, nil
+	}
+	switch typ := strings.ToLower(strings.Fields(rest + " ")[0]); typ {
+	case "numeric", "real", "integer":
+	case "string":
+		attr.dict = new(Dictionary)
+	default:
+		return attr, fmt.Errorf("unsupported type %q of %s", typ, name)
+	}
+	return attr, nil
+}
+
+// LoadARFF loads samples from ARFF (Weka Attribute-Relation File Format),
+// both dense and sparse data with optional instance weights are supported.
+// label is name of class attribute, the last attribute if empty. Nominal
+// attributes are encoded by declared order, string attributes by order of
+// appearance.
+func LoadARFF[T constraints.Float](r io.Reader, label string) ([]model.Sample[T], *Encoding, error) {
+	var bits = int(unsafe.Sizeof(T(0))) * 8
+	var scanner = bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<26)
+	var attrs []arffAttribute
+	var data bool
+	var labelIndex = -1
+	var samples []model.Sample[T]
+	var line int
+	var fail = func(err error) ([]model.Sample[T], *Encoding, error) {
+		return nil, nil, fmt.Errorf("dataloader: line %d: %w", line, err)
+	}
+	var convert = func(attr *arffAttribute, s string) (T, error) {
+		if s == "?" {
+			return model.Missing[T](), nil
+		}
+		s, err := unquoteARFF(s)
+		if err != nil {
+			return 0, err
+		}
+		if attr.dict == nil {
+			v, err := strconv.ParseFloat(s, bits)
+			return T(v), err
+		}
+		if attr.nominal {
+			code, ok := attr.dict.Code(s)
+			if !ok {
+				return 0, fmt.Errorf("undeclared value %q of %s", s, attr.name)
+			}
+			return T(code), nil
+		}
+		return T(attr.dict.add(s)), nil
+	}
+
+	for scanner.Scan() {
+		line++
+		var text = strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || text[0] == '%' {
+			continue
+		}
+		if !data {
+			if text[0] != '@' {
+				return fail(fmt.Errorf("unexpected %q in header", text))
+			}
+			keyword, rest, _ := strings.Cut(text, " ")
+			switch strings.ToLower(strings.TrimSpace(strings.Fields(keyword + " ")[0])) {
+			case "@relation":
+			case "@attribute":
+				attr, err := parseAttributeARFF(rest)
+				if err != nil {
+					return fail(err)
+				}
+				attrs = append(attrs, attr)
+			case "@data":
+				if len(attrs) < 2 {
+					return fail(errors.New("at least 2 attributes required"))
+				}
+				data = true
+				labelIndex = len(attrs) - 1
+				if label != "" {
+					labelIndex = -1
+					for i := range attrs {
+						if attrs[i].name == label {
+							labelIndex = i
+						}
+					}
+					if labelIndex < 0 {
+						return fail(fmt.Errorf("class attribute %q not found", label))
+					}
+				}
+			default:
+				return fail(fmt.Errorf("unknown declaration %q", keyword))
+			}
+			continue
+		}
+
+		var values = make([]T, len(attrs))
+		var weight T
+		var fields []string
+		if text[0] == '{' {
+			// sparse data: {index value, ...}, absent values are 0
+			var end = closeBraceARFF(text)
+			if end < 0 {
+				return fail(errors.New("unterminated sparse data"))
+			}
+			if rest := strings.TrimSpace(text[end+1:]); len(rest) > 0 {
+				// instance weight: {...}, {w}
+				fields = []string{strings.TrimSpace(strings.TrimPrefix(rest, ","))}
+			}
+			if inner := strings.TrimSpace(text[1:end]); inner != "" {
+				for _, entry := range splitARFF(inner, ',') {
+					index, value, err := nextTokenARFF(entry)
+					if err != nil {
+						return fail(err)
+					}
+					i, err := strconv.Atoi(index)
+					if err != nil || i < 0 || i >= len(attrs) {
+						return fail(fmt.Errorf("invalid index %q", index))
+					}
+					if values[i], err = convert(&attrs[i], value); err != nil {
+						return fail(err)
+					}
+				}
+			}
+		} else {
+			fields = splitARFF(text, ',')
+			if len(fields) < len(attrs) {
+				return fail(fmt.Errorf("%d values, want %d", len(fields), len(attrs)))
+			}
+			for i := range attrs {
+				var err error
+				if values[i], err = convert(&attrs[i], fields[i]); err != nil {
+					return fail(err)
+				}
+			}
+			fields = fields[len(attrs):]
+		}
+		if len(fields) > 0 {
+			var w = fields[0]
+			if len(fields) > 1 || !strings.HasPrefix(w, "{") || !strings.HasSuffix(w, "}") {
+				return fail(fmt.Errorf("unexpected %q after values", strings.Join(fields, ",")))
+			}
+			v, err := strconv.ParseFloat(strings.TrimSpace(w[1:len(w)-1]), bits)
+			if err != nil {
+				return fail(err)
+			}
+			weight = T(v)
+		}
+
+		var sample = model.Sample[T]{
+			Attributes: make(tensor.Vector[T], 0, len(attrs)-1),
+			Label:      values[labelIndex],
+			Weight:     weight,
+		}
+		for i, v := range values {
+			if i != labelIndex {
+				sample.Attributes = append(sample.Attributes, v)
+			}
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if !data {
+		return nil, nil, errors.New("dataloader: @data not found")
+	}
+
+	var encoding = NewEncoding()
+	for i := range attrs {
+		if i == labelIndex {
+			encoding.Target = attrs[i].name
+			encoding.Label = attrs[i].dict
+			continue
+		}
+		var attr = len(encoding.Features)
+		encoding.Features = append(encoding.Features, attrs[i].name)
+		if attrs[i].dict != nil {
+			encoding.Attributes[attr] = attrs[i].dict
+		}
+	}
+	return samples, encoding, nil
+}
+
+// LoadARFFFile loads samples from ARFF file
+func LoadARFFFile[T constraints.Float](filename string, label string) ([]model.Sample[T], *Encoding, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	return LoadARFF[T](file, label)
+}
+
+// SaveARFF writes samples in dense ARFF, the class attribute is the last one.
+// Attributes and class are named and typed by encoding: categorical ones are
+// nominal and others are numeric. encoding may be nil. Weights of samples are
+// written as instance weights.
+func SaveARFF[T constraints.Float](w io.Writer, relation string, samples []model.Sample[T], encoding *Encoding) error {
+	if encoding == nil {
+		encoding = NewEncoding()
+	}
+	var bits = int(unsafe.Sizeof(T(0))) * 8
+	var bw = bufio.NewWriter(w)
+	var declare = func(name string, d *Dictionary) {
+		fmt.Fprintf(bw, "@attribute %s ", quoteARFF(name))
+		if d == nil {
+			bw.WriteString("numeric\n")
+			return
+		}
+		bw.WriteByte('{')
+		for i, c := range d.Categories {
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+			bw.WriteString(quoteARFF(c))
+		}
+		bw.WriteString("}\n")
+	}
+	var value = func(buf []byte, v T, d *Dictionary) []byte {
+		if model.IsMissing(v) {
+			return append(buf, '?')
+		}
+		if d != nil {
+			return append(buf, quoteARFF(d.Category(int(v)))...)
+		}
+		return strconv.AppendFloat(buf, float64(v), 'g', -1, bits)
+	}
+
+	fmt.Fprintf(bw, "@relation %s\n\n", quoteARFF(relation))
+	var dim int
+	if len(samples) > 0 {
+		dim = samples[0].Attributes.Dim()
+	}
+	for j := 0; j < dim; j++ {
+		declare(featureName(encoding, j), encoding.Attributes[j])
+	}
+	declare(targetName(encoding), encoding.Label)
+	bw.WriteString("\n@data\n")
+	var buf []byte
+	for i := range samples {
+		buf = buf[:0]
+		for j, v := range samples[i].Attributes {
+			buf = value(buf, v, encoding.Attributes[j])
+			buf = append(buf, ',')
+		}
+		buf = value(buf, samples[i].Label, encoding.Label)
+		if samples[i].Weight != 0 {
+			buf = append(buf, ", {"...)
+			buf = strconv.AppendFloat(buf, float64(samples[i].Weight), 'g', -1, bits)
+			buf = append(buf, '}')
+		}
+		buf = append(buf, '\n')
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -1,10 +1,13 @@
 package dataloader
 
 import (
+	"context"
 	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"runtime"
 	"strconv"
 	"strings"
 	"unsafe"
@@ -13,7 +16,6 @@ import (
 	"github.com/gopherd/doge/constraints"
 	"github.com/gopherd/doge/math/mathutil"
 	"github.com/gopherd/doge/math/tensor"
-	"github.com/gopherd/doge/operator"
 )
 
 type csvOptions struct {
@@ -21,12 +23,22 @@ type csvOptions struct {
 	trimColumnHeader bool
 	rows, columns    int
 	nolabel          bool
+	workers          int
+	encoding         *Encoding
+	onehot           bool
+	label            string   // name of label column
+	labelIndex       int      // index of label column in record, -1 if unspecified
+	selected         []string // names of selected feature columns
+	dropped          []string // names of dropped feature columns
+	weight           string   // name of weight column
 }
 
 func defaultCSVOptions() csvOptions {
 	return csvOptions{
 		trimRowHeader:    true,
 		trimColumnHeader: false,
+		workers:          runtime.NumCPU(),
+		labelIndex:       -1,
 	}
 }
 
@@ -68,82 +80,629 @@ func WithCSVNoLabel(nolabel bool) CSVOption {
 	}
 }
 
+// WithCSVWorkers sets number of goroutines parsing records for StreamCSV,
+// default is number of CPUs
+func WithCSVWorkers(n int) CSVOption {
+	return func(opt *csvOptions) {
+		opt.workers = n
+	}
+}
+
+// WithCSVEncoding sets encoding of string columns, e.g. encoding learned from
+// training data. Columns of attributes in encoding are categorical, and new
+// categories are appended to dictionaries.
+func WithCSVEncoding(encoding *Encoding) CSVOption {
+	return func(opt *csvOptions) {
+		opt.encoding = encoding
+	}
+}
+
+// WithCSVOneHot sets whether categorical attributes are expanded by OneHot,
+// only LoadCSV and LoadCSVEncoded support this option.
+func WithCSVOneHot(yes bool) CSVOption {
+	return func(opt *csvOptions) {
+		opt.onehot = yes
+	}
+}
+
+// WithCSVLabel selects label column by name in header row, features are all
+// other columns (except the row header column if WithCSVColumnHeader).
+func WithCSVLabel(name string) CSVOption {
+	return func(opt *csvOptions) {
+		opt.label = name
+	}
+}
+
+// WithCSVLabelIndex selects label column by 0-based index in record, features
+// are all other columns (except the row header column if WithCSVColumnHeader).
+func WithCSVLabelIndex(index int) CSVOption {
+	return func(opt *csvOptions) {
+		opt.labelIndex = index
+	}
+}
+
+// WithCSVSelect selects feature columns by names in header row, attributes
+// of samples are in order of names.
+func WithCSVSelect(names ...string) CSVOption {
+	return func(opt *csvOptions) {
+		opt.selected = names
+	}
+}
+
+// WithCSVDrop drops feature columns by names in header row
+func WithCSVDrop(names ...string) CSVOption {
+	return func(opt *csvOptions) {
+		opt.dropped = names
+	}
+}
+
+// WithCSVWeight reads weights of samples from column by name in header row,
+// the column is not a feature. Empty or missing weight means default weight.
+func WithCSVWeight(name string) CSVOption {
+	return func(opt *csvOptions) {
+		opt.weight = name
+	}
+}
+
+// isMissing reports whether the cell is a missing value: empty or "?"
+func isMissing(s string) bool {
+	return len(s) == 0 || s == "?"
+}
+
 func LoadCSVFile[T constraints.Float](filename string, options ...CSVOption) ([]model.Sample[T], error) {
+	samples, _, err := LoadCSVFileEncoded[T](filename, options...)
+	return samples, err
+}
+
+// LoadCSVFileEncoded loads samples from CSV file like LoadCSVEncoded
+func LoadCSVFileEncoded[T constraints.Float](filename string, options ...CSVOption) ([]model.Sample[T], *Encoding, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer file.Close()
-	return LoadCSV[T](file, options...)
+	return LoadCSVEncoded[T](file, options...)
 }
 
 func LoadCSV[T constraints.Float](r io.Reader, options ...CSVOption) ([]model.Sample[T], error) {
-	opt := defaultCSVOptions()
-	opt.apply(options)
+	samples, _, err := LoadCSVEncoded[T](r, options...)
+	return samples, err
+}
+
+// LoadCSVEncoded loads samples from CSV, columns which have any cell not a
+// number are encoded to categories, the encoding is returned with samples so
+// that values can be mapped back to names.
+func LoadCSVEncoded[T constraints.Float](r io.Reader, options ...CSVOption) ([]model.Sample[T], *Encoding, error) {
+	return load(NewCSVReader[T](r, options...))
+}
 
-	reader := csv.NewReader(r)
-	records, err := reader.ReadAll()
+// load reads all records before encoding, so that kinds of columns are
+// determined by all cells rather than cells read before.
+func load[T constraints.Float](reader *CSVReader[T]) ([]model.Sample[T], *Encoding, error) {
+	var rows = make([]row[T], 0, reader.opt.rows)
+	for {
+		record, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		var row = reader.parse(record)
+		for _, column := range row.strings {
+			reader.kinds[column] = categoricalColumn
+		}
+		rows = append(rows, row)
+	}
+	samples, err := reader.encodeBatch(rows)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if reader.records == 0 {
+		return nil, reader.Encoding(), nil
+	}
+	for len(samples) < reader.opt.rows {
+		samples = append(samples, model.Sample[T]{
+			Attributes: make(tensor.Vector[T], reader.opt.columns),
+		})
+	}
+	if reader.opt.onehot {
+		samples = OneHot(samples, reader.Encoding())
+	}
+	return samples, reader.Encoding(), nil
+}
+
+type columnKind int
+
+const (
+	unknownColumn columnKind = iota
+	numericColumn
+	categoricalColumn
+)
+
+// CSVReader reads samples from CSV row by row, so that large files can be
+// processed without loading all records into memory.
+//
+// A column is categorical if its first known cell is not a number, values of
+// categorical columns are encoded to codes of dictionaries in Encoding. Since
+// samples read can't be changed, reading a string after numbers in a column
+// is an error, use LoadCSV which sees all cells before encoding or specify
+// categorical columns by WithCSVEncoding for such columns.
+type CSVReader[T constraints.Float] struct {
+	reader   recordReader
+	opt      csvOptions
+	bits     int
+	records  int // number of records read
+	samples  int // number of samples read
+	encoding *Encoding
+	kinds    []columnKind // kinds of attributes followed by label
+	indices  []int        // indices of attributes in record, -1 if absent
+	label    int          // index of label in record, -1 if no label
+	weight   int          // index of weight in record, -1 if no weight
+}
+
+// recordReader reads records of cells, e.g. csv.Reader
+type recordReader interface {
+	Read() ([]string, error)
+}
+
+// NewCSVReader creates a CSVReader which reads from r
+func NewCSVReader[T constraints.Float](r io.Reader, options ...CSVOption) *CSVReader[T] {
+	return newReader[T](csv.NewReader(r), options...)
+}
+
+func newReader[T constraints.Float](r recordReader, options ...CSVOption) *CSVReader[T] {
+	var reader = &CSVReader[T]{
+		reader: r,
+		opt:    defaultCSVOptions(),
+		bits:   int(unsafe.Sizeof(T(0))) * 8,
+	}
+	reader.opt.apply(options)
+	reader.encoding = reader.opt.encoding
+	if reader.encoding == nil {
+		reader.encoding = NewEncoding()
+	} else if reader.encoding.Attributes == nil {
+		reader.encoding.Attributes = make(map[int]*Dictionary)
+	}
+	return reader
+}
+
+// Encoding returns encoding of string columns read so far. For Stream, it's
+// safe to use after the channel closed.
+func (r *CSVReader[T]) Encoding() *Encoding {
+	return r.encoding
+}
+
+// next returns record of next sample
+func (r *CSVReader[T]) next() ([]string, error) {
+	for {
+		if r.opt.rows > 0 && r.samples == r.opt.rows {
+			return nil, io.EOF
+		}
+		record, err := r.reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		r.records++
+		if r.kinds == nil {
+			if err := r.layout(record); err != nil {
+				return nil, err
+			}
+		}
+		if r.opt.trimRowHeader && r.records == 1 {
+			continue
+		}
+		r.samples++
+		return record, nil
+	}
+}
+
+// layout determines columns of attributes and label by the first record
+func (r *CSVReader[T]) layout(record []string) error {
+	var opt = &r.opt
+	var offset = mathutil.Predict[int](opt.trimColumnHeader)
+	var names []string
+	if opt.trimRowHeader {
+		names = make([]string, len(record))
+		for i := range record {
+			names[i] = strings.TrimSpace(record[i])
+		}
+	}
+	var find = func(name string) (int, error) {
+		for i := offset; i < len(names); i++ {
+			if names[i] == name {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("dataloader: column %q not found in header", name)
+	}
+
+	r.label = -1
+	r.weight = -1
+	if opt.weight != "" {
+		index, err := find(opt.weight)
+		if err != nil {
+			return err
+		}
+		r.weight = index
+		if !opt.nolabel && opt.label == "" && opt.labelIndex < 0 {
+			// label is the last column except weight
+			r.label = len(record) - 1
+			if r.label == r.weight {
+				r.label--
+			}
+		}
+	}
+	if !opt.nolabel {
+		if opt.label != "" {
+			index, err := find(opt.label)
+			if err != nil {
+				return err
+			}
+			r.label = index
+		} else if opt.labelIndex >= 0 {
+			if opt.labelIndex >= len(record) {
+				return fmt.Errorf("dataloader: label column %d out of range", opt.labelIndex)
+			}
+			r.label = opt.labelIndex
+		}
 	}
+	if r.label < 0 && r.weight < 0 {
+		// attributes are followed by label
+		var columns = opt.columns
+		if columns < 1 {
+			columns = len(record) - offset - mathutil.Predict[int](!opt.nolabel)
+		}
+		r.indices = make([]int, columns)
+		for i := range r.indices {
+			r.indices[i] = offset + i
+		}
+		if !opt.nolabel {
+			r.label = offset + columns
+		}
+	} else {
+		for i := offset; i < len(record); i++ {
+			if i != r.label && i != r.weight {
+				r.indices = append(r.indices, i)
+			}
+		}
+	}
+	if len(opt.selected) > 0 {
+		r.indices = r.indices[:0]
+		for _, name := range opt.selected {
+			index, err := find(name)
+			if err != nil {
+				return err
+			}
+			r.indices = append(r.indices, index)
+		}
+	}
+	if len(opt.dropped) > 0 {
+		var dropped = make(map[int]bool)
+		for _, name := range opt.dropped {
+			index, err := find(name)
+			if err != nil {
+				return err
+			}
+			dropped[index] = true
+		}
+		var n int
+		for _, index := range r.indices {
+			if !dropped[index] {
+				r.indices[n] = index
+				n++
+			}
+		}
+		r.indices = r.indices[:n]
+	}
+	if opt.columns < 1 {
+		opt.columns = len(r.indices)
+		if opt.columns < 1 {
+			return errors.New("columns must be greater than 0")
+		}
+	}
+	for len(r.indices) < opt.columns {
+		r.indices = append(r.indices, -1)
+	}
+	r.indices = r.indices[:opt.columns]
+
+	if names != nil {
+		r.encoding.Features = make([]string, opt.columns)
+		for i, index := range r.indices {
+			if index >= 0 && index < len(names) {
+				r.encoding.Features[i] = names[index]
+			}
+		}
+		if r.label >= 0 && r.label < len(names) {
+			r.encoding.Target = names[r.label]
+		}
+	}
+
+	r.kinds = make([]columnKind, opt.columns+1)
+	for attr := range r.encoding.Attributes {
+		if attr < opt.columns {
+			r.kinds[attr] = categoricalColumn
+		}
+	}
+	if r.encoding.Label != nil {
+		r.kinds[opt.columns] = categoricalColumn
+	}
+	return nil
+}
+
+// nextBatch returns records of at most n samples, error is returned with
+// records read before the error.
+func (r *CSVReader[T]) nextBatch(n int) ([][]string, error) {
+	var records = make([][]string, 0, n)
+	for len(records) < n {
+		record, err := r.next()
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Read reads next sample, io.EOF is returned if no more samples
+func (r *CSVReader[T]) Read() (model.Sample[T], error) {
+	record, err := r.next()
+	if err != nil {
+		return model.Sample[T]{}, err
+	}
+	var row = r.parse(record)
+	return row.sample, r.encode(&row)
+}
+
+// ReadBatch reads at most n samples, io.EOF is returned only if no samples read
+func (r *CSVReader[T]) ReadBatch(n int) ([]model.Sample[T], error) {
+	records, err := r.nextBatch(n)
 	if len(records) == 0 {
-		return nil, nil
+		return nil, err
+	}
+	samples, eerr := r.encodeBatch(r.parseBatch(records))
+	if eerr != nil {
+		return samples, eerr
 	}
-	var bits = int(unsafe.Sizeof(T(0))) * 8
-	var samples = make([]model.Sample[T], 0, operator.If(opt.rows > 0, opt.rows, len(records)))
+	if err != nil && err != io.EOF {
+		return samples, err
+	}
+	return samples, nil
+}
+
+// row is a parsed record, cells not parsed as number are left to encode
+type row[T constraints.Float] struct {
+	sample  model.Sample[T]
+	record  []string
+	strings []int // columns of cells not parsed as number, label column is len(attributes)
+	err     error
+}
+
+func (r *CSVReader[T]) parseBatch(records [][]string) []row[T] {
+	var rows = make([]row[T], len(records))
 	for i, record := range records {
-		if opt.columns < 1 {
-			opt.columns = len(record) - mathutil.Predict[int](!opt.nolabel)
-			if opt.columns < 1 {
-				return nil, errors.New("columns must be greater than 0")
+		rows[i] = r.parse(record)
+	}
+	return rows
+}
+
+func (r *CSVReader[T]) encodeBatch(rows []row[T]) ([]model.Sample[T], error) {
+	var samples = make([]model.Sample[T], 0, len(rows))
+	for i := range rows {
+		if err := r.encode(&rows[i]); err != nil {
+			return samples, err
+		}
+		samples = append(samples, rows[i].sample)
+	}
+	return samples, nil
+}
+
+// cell returns trimmed cell of column, label column is opt.columns
+func (r *CSVReader[T]) cell(record []string, column int) (string, bool) {
+	var j = r.label
+	if column < r.opt.columns {
+		j = r.indices[column]
+	}
+	if j < 0 || j >= len(record) {
+		return "", false
+	}
+	return strings.TrimSpace(record[j]), true
+}
+
+// parse converts numeric cells of record, it's safe for concurrent use once
+// columns have been determined by the first record.
+func (r *CSVReader[T]) parse(record []string) row[T] {
+	var opt = &r.opt
+	var result = row[T]{record: record}
+	var n = opt.columns + mathutil.Predict[int](!opt.nolabel)
+	var values = make(tensor.Vector[T], n)
+	for column := 0; column < n; column++ {
+		s, ok := r.cell(record, column)
+		if !ok {
+			continue
+		}
+		if isMissing(s) {
+			values[column] = model.Missing[T]()
+			continue
+		}
+		value, err := strconv.ParseFloat(s, r.bits)
+		if err != nil {
+			result.strings = append(result.strings, column)
+			continue
+		}
+		values[column] = T(value)
+	}
+	result.sample.Attributes = values[:opt.columns:opt.columns]
+	if !opt.nolabel {
+		result.sample.Label = values[opt.columns]
+	}
+	if r.weight >= 0 && r.weight < len(record) {
+		if s := strings.TrimSpace(record[r.weight]); !isMissing(s) {
+			weight, err := strconv.ParseFloat(s, r.bits)
+			if err != nil {
+				result.err = fmt.Errorf("dataloader: invalid weight %q", s)
 			}
+			result.sample.Weight = T(weight)
 		}
-		if opt.rows > 0 && len(samples) == opt.rows {
+	}
+	return result
+}
+
+// encode encodes string cells of the row to categories in order of rows,
+// so it must be called sequentially.
+func (r *CSVReader[T]) encode(row *row[T]) error {
+	if row.err != nil {
+		return row.err
+	}
+	for _, column := range row.strings {
+		if r.kinds[column] == numericColumn {
+			s, _ := r.cell(row.record, column)
+			return fmt.Errorf("dataloader: column %d has string %q after numbers", column, s)
+		}
+		r.kinds[column] = categoricalColumn
+	}
+	for column, kind := range r.kinds {
+		if column == r.opt.columns && r.opt.nolabel {
 			break
 		}
-		if opt.trimRowHeader && i == 0 {
+		s, ok := r.cell(row.record, column)
+		if !ok || isMissing(s) {
 			continue
 		}
-		var sample model.Sample[T]
-		sample.Attributes = make(tensor.Vector[T], 0, opt.columns)
-		for j, s := range record {
-			if len(sample.Attributes) == opt.columns {
-				if !opt.nolabel {
-					label, err := strconv.ParseFloat(s, bits)
-					if err != nil {
-						return nil, err
-					}
-					sample.Label = T(label)
-				}
-				break
+		switch kind {
+		case unknownColumn:
+			r.kinds[column] = numericColumn
+		case categoricalColumn:
+			var code = T(r.dictionary(column).add(s))
+			if column == r.opt.columns {
+				row.sample.Label = code
+			} else {
+				row.sample.Attributes[column] = code
 			}
-			if opt.trimColumnHeader && j == 0 {
-				continue
+		}
+	}
+	return nil
+}
+
+func (r *CSVReader[T]) dictionary(column int) *Dictionary {
+	if column == r.opt.columns {
+		if r.encoding.Label == nil {
+			r.encoding.Label = new(Dictionary)
+		}
+		return r.encoding.Label
+	}
+	d, ok := r.encoding.Attributes[column]
+	if !ok {
+		d = new(Dictionary)
+		r.encoding.Attributes[column] = d
+	}
+	return d
+}
+
+// Batch is a batch of samples streamed by StreamCSV
+type Batch[T constraints.Float] struct {
+	Samples []model.Sample[T]
+	Err     error
+}
+
+// StreamCSV reads samples from CSV in batches of batchSize, see CSVReader.Stream
+func StreamCSV[T constraints.Float](ctx context.Context, r io.Reader, batchSize int, options ...CSVOption) <-chan Batch[T] {
+	return NewCSVReader[T](r, options...).Stream(ctx, batchSize)
+}
+
+// Stream reads samples in batches of batchSize, records are parsed by worker
+// goroutines (see WithCSVWorkers) while batches are encoded and delivered in
+// order. The channel is closed after all samples read, an error occurred
+// (delivered as the last batch) or ctx done.
+func (r *CSVReader[T]) Stream(ctx context.Context, batchSize int) <-chan Batch[T] {
+	var workers = r.opt.workers
+	if workers < 1 {
+		workers = 1
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	type result struct {
+		rows []row[T]
+		err  error
+	}
+	type job struct {
+		records [][]string
+		result  chan result
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var out = make(chan Batch[T], workers)
+	var jobs = make(chan job, workers)
+	// pending holds results of batches in order of reading
+	var pending = make(chan chan result, workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := range jobs {
+				j.result <- result{rows: r.parseBatch(j.records)}
 			}
-			s = strings.TrimSpace(s)
-			if len(s) == 0 {
-				sample.Attributes = append(sample.Attributes, 0)
-				continue
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		for {
+			records, err := r.nextBatch(batchSize)
+			if err == io.EOF {
+				err = nil
+			}
+			if len(records) == 0 && err == nil {
+				return
+			}
+			var res = make(chan result, 1)
+			select {
+			case pending <- res:
+			case <-ctx.Done():
+				return
+			}
+			if len(records) > 0 {
+				jobs <- job{records: records, result: res}
 			}
-			value, err := strconv.ParseFloat(s, bits)
 			if err != nil {
-				return nil, err
+				if len(records) > 0 {
+					// deliver the error after parsed records
+					res = make(chan result, 1)
+					select {
+					case pending <- res:
+					case <-ctx.Done():
+						return
+					}
+				}
+				res <- result{err: err}
+				return
 			}
-			sample.Attributes = append(sample.Attributes, T(value))
 		}
-		if len(sample.Attributes) < opt.columns {
-			if cap(sample.Attributes) >= opt.columns {
-				sample.Attributes = sample.Attributes[:opt.columns]
-			} else {
-				sample.Attributes = append(sample.Attributes, make([]T, opt.columns-len(sample.Attributes))...)
+	}()
+
+	go func() {
+		defer close(out)
+		defer cancel()
+		for res := range pending {
+			var parsed result
+			select {
+			case parsed = <-res:
+			case <-ctx.Done():
+				return
+			}
+			var batch = Batch[T]{Err: parsed.err}
+			if batch.Err == nil {
+				batch.Samples, batch.Err = r.encodeBatch(parsed.rows)
+			}
+			select {
+			case out <- batch:
+			case <-ctx.Done():
+				return
+			}
+			if batch.Err != nil {
+				return
 			}
 		}
-		samples = append(samples, sample)
-	}
-	for len(samples) < opt.rows {
-		samples = append(samples, model.Sample[T]{
-			Attributes: make(tensor.Vector[T], opt.columns),
-		})
-	}
-	return samples, nil
+	}()
+	return out
 }
@@ -0,0 +1,413 @@
+package dataloader_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gopherd/ml/dataloader"
+	"github.com/gopherd/ml/model"
+)
+
+func makeCSV(n int) string {
+	var sb strings.Builder
+	sb.WriteString("x,y,label\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "%d,%d.5,%d\n", i, i*2, i%3)
+	}
+	return sb.String()
+}
+
+func collect(t *testing.T, ch <-chan dataloader.Batch[float64]) ([]model.Sample[float64], error) {
+	var samples []model.Sample[float64]
+	for batch := range ch {
+		if batch.Err != nil {
+			return samples, batch.Err
+		}
+		samples = append(samples, batch.Samples...)
+	}
+	return samples, nil
+}
+
+func TestCSVReader(t *testing.T) {
+	var data = makeCSV(10)
+	want, err := dataloader.LoadCSV[float64](strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("load csv error: %v", err)
+	}
+	var reader = dataloader.NewCSVReader[float64](strings.NewReader(data))
+	var got []model.Sample[float64]
+	for {
+		batch, err := reader.ReadBatch(3)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read batch error: %v", err)
+		}
+		got = append(got, batch...)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestStreamCSV(t *testing.T) {
+	var data = makeCSV(1000)
+	want, err := dataloader.LoadCSV[float64](strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("load csv error: %v", err)
+	}
+	for _, workers := range []int{1, 4} {
+		got, err := collect(t, dataloader.StreamCSV[float64](context.Background(), strings.NewReader(data), 7,
+			dataloader.WithCSVWorkers(workers),
+		))
+		if err != nil {
+			t.Fatalf("stream csv error: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("workers %d: streamed samples mismatch", workers)
+		}
+	}
+
+	got, err := collect(t, dataloader.StreamCSV[float64](context.Background(), strings.NewReader(data), 64,
+		dataloader.WithCSVRows(100),
+	))
+	if err != nil || len(got) != 100 {
+		t.Fatalf("stream 100 rows: got %d samples, error %v", len(got), err)
+	}
+}
+
+func TestStreamCSVError(t *testing.T) {
+	var data = makeCSV(100) + "1,x,2\n" + makeCSV(10)
+	got, err := collect(t, dataloader.StreamCSV[float64](context.Background(), strings.NewReader(data), 8))
+	if err == nil {
+		t.Fatalf("want error")
+	}
+	if len(got) > 101 {
+		t.Fatalf("samples after error delivered: %d", len(got))
+	}
+}
+
+func TestStreamCSVCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var ch = dataloader.StreamCSV[float64](ctx, strings.NewReader(makeCSV(10000)), 10)
+	<-ch
+	cancel()
+	for range ch {
+	}
+}
+
+func TestCategorical(t *testing.T) {
+	samples, encoding, err := dataloader.LoadCSVFileEncoded[float64]("../testdata/watermelon/raw/data.csv",
+		dataloader.WithCSVColumnHeader(true),
+	)
+	if err != nil {
+		t.Fatalf("load raw watermelon error: %v", err)
+	}
+	if len(samples) != 17 || samples[0].Attributes.Dim() != 8 {
+		t.Fatalf("got %d samples of %d attributes", len(samples), samples[0].Attributes.Dim())
+	}
+	for attr := 0; attr < 8; attr++ {
+		if want := attr < 6; encoding.Categorical(attr) != want {
+			t.Fatalf("attribute %d: categorical %v, want %v", attr, !want, want)
+		}
+	}
+	if name := encoding.LabelName(samples[0].Label); name != "是" {
+		t.Fatalf("label of first sample: got %q, want 是", name)
+	}
+	if name := encoding.Attributes[3].Category(int(samples[10].Attributes[3])); name != "模糊" {
+		t.Fatalf("texture of 11th sample: got %q, want 模糊", name)
+	}
+	if samples[0].Attributes[6] != 0.697 {
+		t.Fatalf("density of first sample: got %v", samples[0].Attributes[6])
+	}
+
+	// one-hot: 3+3+3+3+3+2 indicators and 2 continuous attributes
+	var onehot = dataloader.OneHot(samples, encoding)
+	if dim := onehot[0].Attributes.Dim(); dim != 19 {
+		t.Fatalf("one-hot dimension: got %d, want 19", dim)
+	}
+	var sum float64
+	for _, v := range onehot[0].Attributes[:3] {
+		sum += v
+	}
+	if sum != 1 {
+		t.Fatalf("one-hot color of first sample: %v", onehot[0].Attributes[:3])
+	}
+
+	// test data reuses dictionaries of training data
+	var data = "编号,色泽,根蒂,敲声,纹理,脐部,触感,密度,含糖率,好瓜\n1,乌黑,稍蜷,浊响,清晰,凹陷,软粘,0.5,0.2,否\n"
+	test, err := dataloader.LoadCSV[float64](strings.NewReader(data),
+		dataloader.WithCSVColumnHeader(true),
+		dataloader.WithCSVEncoding(encoding),
+	)
+	if err != nil {
+		t.Fatalf("load test data error: %v", err)
+	}
+	if test[0].Attributes[0] != samples[1].Attributes[0] || test[0].Label != samples[9].Label {
+		t.Fatalf("test data encoded differently: %v", test[0])
+	}
+}
+
+func TestMixedColumn(t *testing.T) {
+	// strings after numbers make the column categorical for LoadCSV
+	var data = "id,label\n1,0\n2,1\n3a,1\n2,0\n"
+	samples, encoding, err := dataloader.LoadCSVEncoded[float64](strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if !encoding.Categorical(0) || encoding.Attributes[0].Len() != 3 {
+		t.Fatalf("id not categorical: %v", encoding.Attributes[0])
+	}
+	if samples[1].Attributes[0] != samples[3].Attributes[0] || encoding.Attributes[0].Category(int(samples[2].Attributes[0])) != "3a" {
+		t.Fatalf("got %v, categories %v", samples, encoding.Attributes[0].Categories)
+	}
+	// but it's an error for streaming
+	var reader = dataloader.NewCSVReader[float64](strings.NewReader(data))
+	for err == nil {
+		_, err = reader.Read()
+	}
+	if err == io.EOF || !strings.Contains(err.Error(), "after numbers") {
+		t.Fatalf("read: got error %v, want string after numbers", err)
+	}
+	samples, encoding, err = dataloader.LoadCSVEncoded[float64](strings.NewReader("a,label\n,0\nx,1\n1,0\n"))
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if !model.IsMissing(samples[0].Attributes[0]) || encoding.Attributes[0].Len() != 2 {
+		t.Fatalf("got %v, categories %v", samples, encoding.Attributes[0].Categories)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	var data = "id,a,label,b,c\n1,10,0,20,30\n2,11,1,21,31\n"
+	samples, encoding, err := dataloader.LoadCSVEncoded[float64](strings.NewReader(data),
+		dataloader.WithCSVLabel("label"),
+		dataloader.WithCSVDrop("id"),
+	)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if !reflect.DeepEqual(encoding.Features, []string{"a", "b", "c"}) || encoding.Target != "label" {
+		t.Fatalf("got features %v of target %q", encoding.Features, encoding.Target)
+	}
+	if got := samples[1]; got.Label != 1 || !reflect.DeepEqual([]float64(got.Attributes), []float64{11, 21, 31}) {
+		t.Fatalf("got sample %v", got)
+	}
+
+	samples, encoding, err = dataloader.LoadCSVEncoded[float64](strings.NewReader(data),
+		dataloader.WithCSVLabelIndex(2),
+		dataloader.WithCSVSelect("c", "a"),
+	)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if !reflect.DeepEqual(encoding.Features, []string{"c", "a"}) {
+		t.Fatalf("got features %v", encoding.Features)
+	}
+	if got := samples[0]; got.Label != 0 || !reflect.DeepEqual([]float64(got.Attributes), []float64{30, 10}) {
+		t.Fatalf("got sample %v", got)
+	}
+
+	if _, err := dataloader.LoadCSV[float64](strings.NewReader(data), dataloader.WithCSVLabel("y")); err == nil {
+		t.Fatalf("want error for unknown label column")
+	}
+}
+
+func TestLIBSVM(t *testing.T) {
+	var data = "+1 1:0.5 3:2 # comment\n-1 qid:3 2:1.5\n\n1 4:-1\n"
+	samples, err := dataloader.LoadLIBSVM[float64](strings.NewReader(data), 0)
+	if err != nil {
+		t.Fatalf("load libsvm error: %v", err)
+	}
+	if len(samples) != 3 || samples[0].Attributes.Dim() != 4 {
+		t.Fatalf("got %d samples of %d attributes", len(samples), samples[0].Attributes.Dim())
+	}
+	if !reflect.DeepEqual([]float64(samples[0].Attributes), []float64{0.5, 0, 2, 0}) || samples[1].Label != -1 {
+		t.Fatalf("got samples %v", samples)
+	}
+	var sb strings.Builder
+	if err := dataloader.SaveLIBSVM(&sb, samples); err != nil {
+		t.Fatalf("save libsvm error: %v", err)
+	}
+	if want := "1 1:0.5 3:2\n-1 2:1.5\n1 4:-1\n"; sb.String() != want {
+		t.Fatalf("saved %q, want %q", sb.String(), want)
+	}
+	if _, err := dataloader.LoadLIBSVM[float64](strings.NewReader(data), 3); err == nil {
+		t.Fatalf("want error for index out of dimension")
+	}
+}
+
+const weatherARFF = `% weather data
+@relation weather
+
+@attribute outlook {sunny, overcast, 'rainy day'}
+@attribute temperature numeric
+@attribute windy {TRUE, FALSE}
+@attribute play {yes, no}
+
+@data
+sunny,85,FALSE,no
+overcast,?,TRUE,yes, {2}
+'rainy day',70,TRUE,no
+{0 overcast, 1 64, 3 no}
+`
+
+func TestARFF(t *testing.T) {
+	samples, encoding, err := dataloader.LoadARFF[float64](strings.NewReader(weatherARFF), "")
+	if err != nil {
+		t.Fatalf("load arff error: %v", err)
+	}
+	if len(samples) != 4 || encoding.Target != "play" || !reflect.DeepEqual(encoding.Features, []string{"outlook", "temperature", "windy"}) {
+		t.Fatalf("got %d samples, features %v, target %q", len(samples), encoding.Features, encoding.Target)
+	}
+	if encoding.ValueName(0, samples[2].Attributes[0]) != "rainy day" || encoding.LabelName(samples[0].Label) != "no" {
+		t.Fatalf("got sample %v", samples[2])
+	}
+	if !model.IsMissing(samples[1].Attributes[1]) || samples[1].Weight != 2 {
+		t.Fatalf("got sample %v", samples[1])
+	}
+	// absent nominal value of sparse data is the first declared value
+	if got := samples[3]; got.Attributes[0] != 1 || got.Attributes[1] != 64 || got.Attributes[2] != 0 || got.Label != 1 {
+		t.Fatalf("got sparse sample %v", got)
+	}
+
+	var sb strings.Builder
+	if err := dataloader.SaveARFF(&sb, "weather", samples, encoding); err != nil {
+		t.Fatalf("save arff error: %v", err)
+	}
+	again, encoding2, err := dataloader.LoadARFF[float64](strings.NewReader(sb.String()), "")
+	if err != nil {
+		t.Fatalf("load saved arff error: %v\n%s", err, sb.String())
+	}
+	if !reflect.DeepEqual(encoding2.Features, encoding.Features) || len(again) != len(samples) {
+		t.Fatalf("saved arff mismatch:\n%s", sb.String())
+	}
+	for i := range samples {
+		if again[i].Label != samples[i].Label || again[i].Weight != samples[i].Weight || again[i].Attributes[0] != samples[i].Attributes[0] {
+			t.Fatalf("sample %d: got %v, want %v", i, again[i], samples[i])
+		}
+	}
+
+	if _, _, err := dataloader.LoadARFF[float64](strings.NewReader(weatherARFF), "humidity"); err == nil {
+		t.Fatalf("want error for unknown class attribute")
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	var data = `{"color":"green","size":1.5,"label":"yes"}
+{"size":null,"color":"red","label":"no"}
+`
+	samples, encoding, err := dataloader.LoadJSONL[float64](strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("load jsonl error: %v", err)
+	}
+	if len(samples) != 2 || !reflect.DeepEqual(encoding.Features, []string{"color", "size"}) {
+		t.Fatalf("got %d samples, features %v", len(samples), encoding.Features)
+	}
+	if encoding.ValueName(0, samples[1].Attributes[0]) != "red" || !model.IsMissing(samples[1].Attributes[1]) || encoding.LabelName(samples[1].Label) != "no" {
+		t.Fatalf("got sample %v", samples[1])
+	}
+
+	raw, encoding, err := dataloader.LoadCSVFileEncoded[float64]("../testdata/watermelon/raw/data.csv",
+		dataloader.WithCSVColumnHeader(true),
+	)
+	if err != nil {
+		t.Fatalf("load raw watermelon error: %v", err)
+	}
+	var sb strings.Builder
+	if err := dataloader.SaveJSONL(&sb, raw, encoding); err != nil {
+		t.Fatalf("save jsonl error: %v", err)
+	}
+	again, encoding2, err := dataloader.LoadJSONL[float64](strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("load saved jsonl error: %v", err)
+	}
+	if !reflect.DeepEqual(again, raw) || !reflect.DeepEqual(encoding2.Features, encoding.Features) {
+		t.Fatalf("saved jsonl mismatch:\n%s", sb.String())
+	}
+}
+
+func TestSaveCSV(t *testing.T) {
+	var samples = []model.Sample[float64]{
+		{Attributes: []float64{1.25, model.Missing[float64]()}, Label: 1, Weight: 2},
+		{Attributes: []float64{-3, 0.5}, Label: 0},
+	}
+	var sb strings.Builder
+	err := dataloader.SaveCSV(&sb, samples,
+		dataloader.WithSavePrecision(1),
+		dataloader.WithSaveWeight(true),
+		dataloader.WithSaveColumn("score", []float64{0.75, 0.25}),
+	)
+	if err != nil {
+		t.Fatalf("save csv error: %v", err)
+	}
+	if want := "x0,x1,weight,label,score\n1.2,,2.0,1.0,0.8\n-3.0,0.5,1.0,0.0,0.2\n"; sb.String() != want {
+		t.Fatalf("saved %q, want %q", sb.String(), want)
+	}
+
+	sb.Reset()
+	if err := dataloader.SaveCSV(&sb, samples, dataloader.WithSaveWeight(true)); err != nil {
+		t.Fatalf("save csv error: %v", err)
+	}
+	again, err := dataloader.LoadCSV[float64](strings.NewReader(sb.String()), dataloader.WithCSVWeight("weight"))
+	if err != nil {
+		t.Fatalf("load saved csv error: %v", err)
+	}
+	samples[1].Weight = 1
+	if len(again) != 2 || again[0].Attributes[0] != 1.25 || !model.IsMissing(again[0].Attributes[1]) ||
+		again[0].Weight != 2 || again[1].Weight != 1 || again[1].Label != 0 {
+		t.Fatalf("got %v, want %v", again, samples)
+	}
+
+	if err := dataloader.SaveCSV(&sb, samples, dataloader.WithSaveColumn("x", []float64{1})); err == nil {
+		t.Fatalf("want error for mismatched column length")
+	}
+}
+
+func TestSaveCSVEncoded(t *testing.T) {
+	samples, encoding, err := dataloader.LoadCSVFileEncoded[float64]("../testdata/watermelon/raw/data.csv",
+		dataloader.WithCSVLabel("好瓜"),
+		dataloader.WithCSVDrop("编号"),
+	)
+	if err != nil {
+		t.Fatalf("load raw watermelon error: %v", err)
+	}
+	var predictions = make([]float64, len(samples))
+	var probabilities = make([][]float64, len(samples))
+	for i := range samples {
+		predictions[i] = 1 - samples[i].Label
+		probabilities[i] = []float64{0.5, 0.5}
+	}
+	var sb strings.Builder
+	err = dataloader.SaveCSV(&sb, samples,
+		dataloader.WithSaveEncoding(encoding),
+		dataloader.WithSavePredictions(predictions),
+		dataloader.WithSaveProbabilities(probabilities),
+	)
+	if err != nil {
+		t.Fatalf("save csv error: %v", err)
+	}
+	var lines = strings.Split(sb.String(), "\n")
+	if want := "色泽,根蒂,敲声,纹理,脐部,触感,密度,含糖率,好瓜,prediction,p(是),p(否)"; lines[0] != want {
+		t.Fatalf("header: got %q, want %q", lines[0], want)
+	}
+	if want := "青绿,蜷缩,浊响,清晰,凹陷,硬滑,0.697,0.46,是,否,0.5,0.5"; lines[1] != want {
+		t.Fatalf("first row: got %q, want %q", lines[1], want)
+	}
+	again, _, err := dataloader.LoadCSVEncoded[float64](strings.NewReader(sb.String()),
+		dataloader.WithCSVLabel("好瓜"),
+		dataloader.WithCSVDrop("prediction", "p(是)", "p(否)"),
+		dataloader.WithCSVEncoding(encoding),
+	)
+	if err != nil {
+		t.Fatalf("load saved csv error: %v", err)
+	}
+	if !reflect.DeepEqual(again, samples) {
+		t.Fatalf("saved csv mismatch")
+	}
+}
@@ -0,0 +1,131 @@
+package dataloader
+
+import (
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/model"
+)
+
+// Dictionary encodes categories of a string column to integer codes in order
+// of appearance, it's a label encoder.
+type Dictionary struct {
+	Categories []string `json:"categories"`
+	codes      map[string]int
+}
+
+// Len returns number of categories
+func (d *Dictionary) Len() int {
+	return len(d.Categories)
+}
+
+// Code returns code of category s
+func (d *Dictionary) Code(s string) (int, bool) {
+	if d.codes == nil {
+		d.codes = make(map[string]int, len(d.Categories))
+		for i, c := range d.Categories {
+			d.codes[c] = i
+		}
+	}
+	code, ok := d.codes[s]
+	return code, ok
+}
+
+// Category returns category of code, empty string returned if code not found
+func (d *Dictionary) Category(code int) string {
+	if code < 0 || code >= len(d.Categories) {
+		return ""
+	}
+	return d.Categories[code]
+}
+
+// add returns code of category s, s is added if not found
+func (d *Dictionary) add(s string) int {
+	if code, ok := d.Code(s); ok {
+		return code
+	}
+	var code = len(d.Categories)
+	d.Categories = append(d.Categories, s)
+	d.codes[s] = code
+	return code
+}
+
+// Encoding holds names of columns and dictionaries of string columns learned
+// by the loader
+type Encoding struct {
+	Features   []string            `json:"features,omitempty"`   // names of attributes, nil if no header row
+	Target     string              `json:"target,omitempty"`     // name of label column
+	Attributes map[int]*Dictionary `json:"attributes,omitempty"` // dictionaries by index of attribute
+	Label      *Dictionary         `json:"label,omitempty"`      // nil if label is numeric
+}
+
+// NewEncoding creates an empty encoding
+func NewEncoding() *Encoding {
+	return &Encoding{
+		Attributes: make(map[int]*Dictionary),
+	}
+}
+
+// Categorical reports whether the attribute is categorical
+func (e *Encoding) Categorical(attr int) bool {
+	_, ok := e.Attributes[attr]
+	return ok
+}
+
+// AttributeName returns name of attribute, empty string returned if unnamed
+func (e *Encoding) AttributeName(attr int) string {
+	if attr < 0 || attr >= len(e.Features) {
+		return ""
+	}
+	return e.Features[attr]
+}
+
+// ValueName returns category name of value of attribute, empty string
+// returned if the attribute is not categorical
+func (e *Encoding) ValueName(attr int, value float64) string {
+	d, ok := e.Attributes[attr]
+	if !ok {
+		return ""
+	}
+	return d.Category(int(value))
+}
+
+// LabelName returns category name of label, e.g. predicted label
+func (e *Encoding) LabelName(label float64) string {
+	if e.Label == nil {
+		return ""
+	}
+	return e.Label.Category(int(label))
+}
+
+// OneHot expands each categorical attribute of samples into indicator
+// attributes, one per category in order of dictionary. Missing value of a
+// categorical attribute expands to missing values.
+func OneHot[T constraints.Float](samples []model.Sample[T], encoding *Encoding) []model.Sample[T] {
+	if len(encoding.Attributes) == 0 {
+		return samples
+	}
+	var result = make([]model.Sample[T], len(samples))
+	for i := range samples {
+		var x = samples[i].Attributes
+		var y = make(tensor.Vector[T], 0, len(x))
+		for j, v := range x {
+			d, ok := encoding.Attributes[j]
+			if !ok {
+				y = append(y, v)
+				continue
+			}
+			for k := 0; k < d.Len(); k++ {
+				if model.IsMissing(v) {
+					y = append(y, v)
+				} else if int(v) == k {
+					y = append(y, 1)
+				} else {
+					y = append(y, 0)
+				}
+			}
+		}
+		result[i] = samples[i]
+		result[i].Attributes = y
+	}
+	return result
+}
@@ -0,0 +1,192 @@
+package dataloader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"unsafe"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/model"
+)
+
+// jsonlReader reads JSON Lines as records, keys of the first object make up
+// the header row.
+type jsonlReader struct {
+	decoder *json.Decoder
+	header  []string
+	index   map[string]int
+	first   []string // first row pending after header
+}
+
+func (r *jsonlReader) Read() ([]string, error) {
+	if r.first != nil {
+		var record = r.first
+		r.first = nil
+		return record, nil
+	}
+	keys, values, err := r.object()
+	if err != nil {
+		return nil, err
+	}
+	if r.header == nil {
+		r.header = keys
+		r.index = make(map[string]int, len(keys))
+		for i, key := range keys {
+			r.index[key] = i
+		}
+		r.first = values
+		return r.header, nil
+	}
+	var record = make([]string, len(r.header))
+	for i, key := range keys {
+		j, ok := r.index[key]
+		if !ok {
+			return nil, fmt.Errorf("dataloader: key %q not found in first object", key)
+		}
+		record[j] = values[i]
+	}
+	return record, nil
+}
+
+// object reads next object, keys are in order of appearance
+func (r *jsonlReader) object() (keys, values []string, err error) {
+	tok, err := r.decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("dataloader: want JSON object, got %v", tok)
+	}
+	for r.decoder.More() {
+		tok, err := r.decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		var raw json.RawMessage
+		if err := r.decoder.Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+		value, err := jsonValue(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("dataloader: key %q: %w", tok, err)
+		}
+		keys = append(keys, tok.(string))
+		values = append(values, value)
+	}
+	// consume '}'
+	if _, err := r.decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+	return keys, values, nil
+}
+
+// jsonValue converts JSON value to cell: null to missing, string to itself,
+// number and boolean to their literals.
+func jsonValue(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return "", nil
+	}
+	switch raw[0] {
+	case 'n':
+		return "", nil
+	case '"':
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	case '{', '[':
+		return "", fmt.Errorf("nested value not supported")
+	default:
+		return string(raw), nil
+	}
+}
+
+// LoadJSONL loads samples from JSON Lines, each line is an object of a sample
+// keyed by column names, e.g.
+//
+//	{"color":"青绿","density":0.697,"label":"是"}
+//
+// Keys of the first object make up the header row, so options of CSV apply,
+// e.g. WithCSVLabel. null is a missing value, strings are categorical.
+func LoadJSONL[T constraints.Float](r io.Reader, options ...CSVOption) ([]model.Sample[T], *Encoding, error) {
+	var reader = &jsonlReader{decoder: json.NewDecoder(r)}
+	return load(newReader[T](reader, append(options, WithCSVRowHeader(true), WithCSVColumnHeader(false))...))
+}
+
+// LoadJSONLFile loads samples from JSON Lines file
+func LoadJSONLFile[T constraints.Float](filename string, options ...CSVOption) ([]model.Sample[T], *Encoding, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	return LoadJSONL[T](file, options...)
+}
+
+// SaveJSONL writes samples as JSON Lines which can be loaded by LoadJSONL.
+// Keys are names of features and target in encoding, or x0, x1, ... and
+// label if unnamed; categorical values are written as names. encoding may be nil.
+func SaveJSONL[T constraints.Float](w io.Writer, samples []model.Sample[T], encoding *Encoding) error {
+	if encoding == nil {
+		encoding = NewEncoding()
+	}
+	var bw = bufio.NewWriter(w)
+	var bits = int(unsafe.Sizeof(T(0))) * 8
+	var buf []byte
+	for i := range samples {
+		buf = append(buf[:0], '{')
+		for j, v := range samples[i].Attributes {
+			if j > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, featureName(encoding, j))
+			buf = append(buf, ':')
+			buf = appendJSONValue(buf, float64(v), bits, encoding.Attributes[j])
+		}
+		if len(samples[i].Attributes) > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendJSONString(buf, targetName(encoding))
+		buf = append(buf, ':')
+		buf = appendJSONValue(buf, float64(samples[i].Label), bits, encoding.Label)
+		buf = append(buf, '}', '\n')
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func featureName(encoding *Encoding, attr int) string {
+	if name := encoding.AttributeName(attr); name != "" {
+		return name
+	}
+	return "x" + strconv.Itoa(attr)
+}
+
+func targetName(encoding *Encoding) string {
+	if encoding.Target != "" {
+		return encoding.Target
+	}
+	return "label"
+}
+
+func appendJSONString(buf []byte, s string) []byte {
+	data, _ := json.Marshal(s)
+	return append(buf, data...)
+}
+
+func appendJSONValue(buf []byte, v float64, bits int, d *Dictionary) []byte {
+	if model.IsMissing(v) {
+		return append(buf, "null"...)
+	}
+	if d != nil {
+		return appendJSONString(buf, d.Category(int(v)))
+	}
+	return strconv.AppendFloat(buf, v, 'g', -1, bits)
+}
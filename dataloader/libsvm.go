@@ -0,0 +1,126 @@
+package dataloader
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/model"
+)
+
+type sparseEntry[T constraints.Float] struct {
+	index int
+	value T
+}
+
+// LoadLIBSVM loads samples from LIBSVM/SVMlight sparse format:
+//
+//	<label> <index>:<value> <index>:<value> ... # comment
+//
+// Indices are 1-based and absent attributes are 0, qid of SVMlight is ignored.
+// dim is number of attributes, it's inferred by the max index if dim < 1.
+func LoadLIBSVM[T constraints.Float](r io.Reader, dim int) ([]model.Sample[T], error) {
+	var bits = int(unsafe.Sizeof(T(0))) * 8
+	var scanner = bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<26)
+	var labels []T
+	var rows [][]sparseEntry[T]
+	var maxIndex int
+	for line := 1; scanner.Scan(); line++ {
+		var text = scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		var fields = strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		label, err := strconv.ParseFloat(fields[0], bits)
+		if err != nil {
+			return nil, fmt.Errorf("dataloader: line %d: %w", line, err)
+		}
+		var row = make([]sparseEntry[T], 0, len(fields)-1)
+		for _, field := range fields[1:] {
+			var i = strings.IndexByte(field, ':')
+			if i < 0 {
+				return nil, fmt.Errorf("dataloader: line %d: invalid pair %q", line, field)
+			}
+			if field[:i] == "qid" {
+				continue
+			}
+			index, err := strconv.Atoi(field[:i])
+			if err != nil || index < 1 {
+				return nil, fmt.Errorf("dataloader: line %d: invalid index %q", line, field[:i])
+			}
+			value, err := strconv.ParseFloat(field[i+1:], bits)
+			if err != nil {
+				return nil, fmt.Errorf("dataloader: line %d: %w", line, err)
+			}
+			if dim > 0 && index > dim {
+				return nil, fmt.Errorf("dataloader: line %d: index %d out of dimension %d", line, index, dim)
+			}
+			if index > maxIndex {
+				maxIndex = index
+			}
+			row = append(row, sparseEntry[T]{index: index - 1, value: T(value)})
+		}
+		labels = append(labels, T(label))
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if dim < 1 {
+		dim = maxIndex
+	}
+	var samples = make([]model.Sample[T], len(rows))
+	for i, row := range rows {
+		samples[i].Label = labels[i]
+		samples[i].Attributes = make(tensor.Vector[T], dim)
+		for _, e := range row {
+			samples[i].Attributes[e.index] = e.value
+		}
+	}
+	return samples, nil
+}
+
+// LoadLIBSVMFile loads samples from LIBSVM/SVMlight file
+func LoadLIBSVMFile[T constraints.Float](filename string, dim int) ([]model.Sample[T], error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadLIBSVM[T](file, dim)
+}
+
+// SaveLIBSVM writes samples in LIBSVM format, zero attributes are omitted.
+// Missing values can not be represented, they are omitted as zeros.
+func SaveLIBSVM[T constraints.Float](w io.Writer, samples []model.Sample[T]) error {
+	var bits = int(unsafe.Sizeof(T(0))) * 8
+	var bw = bufio.NewWriter(w)
+	var buf []byte
+	for i := range samples {
+		buf = strconv.AppendFloat(buf[:0], float64(samples[i].Label), 'g', -1, bits)
+		for j, v := range samples[i].Attributes {
+			if v == 0 || model.IsMissing(v) {
+				continue
+			}
+			buf = append(buf, ' ')
+			buf = strconv.AppendInt(buf, int64(j+1), 10)
+			buf = append(buf, ':')
+			buf = strconv.AppendFloat(buf, float64(v), 'g', -1, bits)
+		}
+		buf = append(buf, '\n')
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,238 @@
+package dataloader
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"unsafe"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/model"
+)
+
+// column is an extra column written by SaveCSV
+type column struct {
+	name       string
+	length     int
+	value      func(i int) float64
+	prediction bool // values are labels, written as names if label is categorical
+	class      int  // class of probability column, -1 if not a probability column
+}
+
+type saveCSVOptions struct {
+	header    bool
+	precision int
+	weight    bool
+	nolabel   bool
+	encoding  *Encoding
+	columns   []column
+}
+
+func defaultSaveCSVOptions() saveCSVOptions {
+	return saveCSVOptions{
+		header:    true,
+		precision: -1,
+	}
+}
+
+// SaveCSVOption represents an option of SaveCSV
+type SaveCSVOption func(opt *saveCSVOptions)
+
+func (opt *saveCSVOptions) apply(options []SaveCSVOption) {
+	for _, o := range options {
+		o(opt)
+	}
+}
+
+// WithSaveHeader sets whether header row is written, default is true
+func WithSaveHeader(yes bool) SaveCSVOption {
+	return func(opt *saveCSVOptions) {
+		opt.header = yes
+	}
+}
+
+// WithSavePrecision sets number of digits after the decimal point, default
+// is -1 which means the shortest representation
+func WithSavePrecision(precision int) SaveCSVOption {
+	return func(opt *saveCSVOptions) {
+		opt.precision = precision
+	}
+}
+
+// WithSaveWeight sets whether weight column is written before label, it can
+// be loaded by WithCSVWeight("weight")
+func WithSaveWeight(yes bool) SaveCSVOption {
+	return func(opt *saveCSVOptions) {
+		opt.weight = yes
+	}
+}
+
+// WithSaveNoLabel sets whether label column is omitted
+func WithSaveNoLabel(nolabel bool) SaveCSVOption {
+	return func(opt *saveCSVOptions) {
+		opt.nolabel = nolabel
+	}
+}
+
+// WithSaveEncoding names columns and writes categorical values as names by
+// encoding, e.g. encoding returned by LoadCSVEncoded
+func WithSaveEncoding(encoding *Encoding) SaveCSVOption {
+	return func(opt *saveCSVOptions) {
+		opt.encoding = encoding
+	}
+}
+
+// WithSaveColumn appends a numeric column, values[i] is written in row of i-th sample
+func WithSaveColumn[T constraints.Float](name string, values []T) SaveCSVOption {
+	return func(opt *saveCSVOptions) {
+		opt.columns = append(opt.columns, column{
+			name:   name,
+			length: len(values),
+			value:  func(i int) float64 { return float64(values[i]) },
+			class:  -1,
+		})
+	}
+}
+
+// WithSavePredictions appends column "prediction" of predicted labels, which
+// are written as names if label is categorical in encoding
+func WithSavePredictions[T constraints.Float](predictions []T) SaveCSVOption {
+	return func(opt *saveCSVOptions) {
+		opt.columns = append(opt.columns, column{
+			name:       "prediction",
+			length:     len(predictions),
+			value:      func(i int) float64 { return float64(predictions[i]) },
+			prediction: true,
+			class:      -1,
+		})
+	}
+}
+
+// WithSaveProbabilities appends a column "p(c)" for each class c, where
+// probabilities[i][c] is probability of i-th sample being class c and c is
+// named by encoding if label is categorical
+func WithSaveProbabilities[T constraints.Float](probabilities [][]T) SaveCSVOption {
+	return func(opt *saveCSVOptions) {
+		var classes int
+		for _, p := range probabilities {
+			if len(p) > classes {
+				classes = len(p)
+			}
+		}
+		for c := 0; c < classes; c++ {
+			var c = c
+			opt.columns = append(opt.columns, column{
+				length: len(probabilities),
+				value: func(i int) float64 {
+					if c >= len(probabilities[i]) {
+						return 0
+					}
+					return float64(probabilities[i][c])
+				},
+				class: c,
+			})
+		}
+	}
+}
+
+// SaveCSVFile saves samples to CSV file, see SaveCSV
+func SaveCSVFile[T constraints.Float](filename string, samples []model.Sample[T], options ...SaveCSVOption) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := SaveCSV(file, samples, options...); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// SaveCSV writes samples as CSV: attributes, weight (optional), label
+// (optional) and extra columns. Missing values are written as empty cells,
+// so that the CSV can be loaded by LoadCSV again.
+func SaveCSV[T constraints.Float](w io.Writer, samples []model.Sample[T], options ...SaveCSVOption) error {
+	var opt = defaultSaveCSVOptions()
+	opt.apply(options)
+	var encoding = opt.encoding
+	if encoding == nil {
+		encoding = NewEncoding()
+	}
+	for _, c := range opt.columns {
+		if c.length != len(samples) {
+			return errors.New("dataloader: length of column " + strconv.Quote(c.name) + " mismatched with samples")
+		}
+	}
+	var bits = int(unsafe.Sizeof(T(0))) * 8
+	var format = func(v float64, d *Dictionary) string {
+		if model.IsMissing(v) {
+			return ""
+		}
+		if d != nil {
+			return d.Category(int(v))
+		}
+		if opt.precision < 0 {
+			return strconv.FormatFloat(v, 'g', -1, bits)
+		}
+		return strconv.FormatFloat(v, 'f', opt.precision, bits)
+	}
+
+	var writer = csv.NewWriter(w)
+	var dim int
+	if len(samples) > 0 {
+		dim = samples[0].Attributes.Dim()
+	}
+	if opt.header {
+		var header = make([]string, 0, dim+2+len(opt.columns))
+		for j := 0; j < dim; j++ {
+			header = append(header, featureName(encoding, j))
+		}
+		if opt.weight {
+			header = append(header, "weight")
+		}
+		if !opt.nolabel {
+			header = append(header, targetName(encoding))
+		}
+		for _, c := range opt.columns {
+			var name = c.name
+			if c.class >= 0 {
+				name = strconv.Itoa(c.class)
+				if encoding.Label != nil {
+					name = encoding.Label.Category(c.class)
+				}
+				name = "p(" + name + ")"
+			}
+			header = append(header, name)
+		}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+	}
+	var record []string
+	for i := range samples {
+		record = record[:0]
+		for j, v := range samples[i].Attributes {
+			record = append(record, format(float64(v), encoding.Attributes[j]))
+		}
+		if opt.weight {
+			record = append(record, format(float64(model.WeightOf(samples[i])), nil))
+		}
+		if !opt.nolabel {
+			record = append(record, format(float64(samples[i].Label), encoding.Label))
+		}
+		for _, c := range opt.columns {
+			var d *Dictionary
+			if c.prediction {
+				d = encoding.Label
+			}
+			record = append(record, format(c.value(i), d))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -3,6 +3,8 @@
 // C4.5 is an algorithm used to generate a decision tree developed by Ross Quinlan.
 // @see https://en.wikipedia.org/wiki/C4.5_algorithm
 //
+// Samples missing an attribute are handled as Quinlan's C4.5: gain of the
+// attribute is computed on known samples and scaled by ratio of known weights.
 package c45
 
 import (
@@ -12,23 +14,25 @@ import (
 )
 
 func Policy[T constraints.Float](samples []model.Sample[T], attrs []int) int {
-	var total = T(len(samples))
-	var ent = model.SumEntropySet(samples)
+	var total = model.SumWeights(samples)
 
 	// calculate gain and iv for each attribute
 	var gainAndIVs = make([]pair.Pair[T, T], len(attrs))
 	var avgGain, maxGain T
 	for i, attr := range attrs {
-		var gain = ent
+		var known = model.Known(samples, attr)
+		var knownTotal = model.SumWeights(known)
+		var gain = model.WeightedSumEntropySet(known)
 		var iv T
-		for _, s := range model.Group(samples, attr) {
-			var p = T(len(s)) / total
-			gain -= p * model.SumEntropySet(s)
+		for _, s := range model.Group(known, attr) {
+			var p = model.SumWeights(s) / knownTotal
+			gain -= p * model.WeightedSumEntropySet(s)
 			iv += model.Entropy(p)
 		}
 		if iv < model.Epsilon {
 			return i
 		}
+		gain *= knownTotal / total
 		gainAndIVs[i].First = gain
 		gainAndIVs[i].Second = iv
 		avgGain += gain
@@ -36,7 +40,7 @@ func Policy[T constraints.Float](samples []model.Sample[T], attrs []int) int {
 			maxGain = gain
 		}
 	}
-	avgGain /= total
+	avgGain /= T(len(attrs))
 	var filter = maxGain > avgGain
 
 	// select attribute which has maxinum gain ratio from where
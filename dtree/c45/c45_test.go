@@ -3,8 +3,11 @@ package c45_test
 import (
 	"testing"
 
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/dataloader"
 	"github.com/gopherd/ml/dtree"
 	"github.com/gopherd/ml/dtree/c45"
+	"github.com/gopherd/ml/model"
 )
 
 func TestModel(t *testing.T) {
@@ -13,3 +16,64 @@ func TestModel(t *testing.T) {
 	dtree.TestModel("../../testdata/watermelon/v2/data.csv", model, t)
 	t.Logf("\n%v", model.Stringify(nil))
 }
+
+func TestPostPruning(t *testing.T) {
+	type T = float32
+	dtree.TestPruning(c45.Policy[T], dtree.PostPruning, t)
+}
+
+func TestPrePruning(t *testing.T) {
+	type T = float32
+	dtree.TestPruning(c45.Policy[T], dtree.PrePruning, t,
+		dtree.WithValidationCheck[T](true),
+		dtree.WithMinSamplesLeaf[T](1),
+		dtree.WithMinGain[T](0.01),
+	)
+}
+
+func TestHoldout(t *testing.T) {
+	type T = float32
+	dtree.TestHoldout(c45.Policy[T], dtree.PostPruning, t)
+}
+
+func TestMissing(t *testing.T) {
+	type T = float64
+	samples, err := dataloader.LoadCSVFile[T]("../../testdata/watermelon/v2alpha/data.csv")
+	if err != nil {
+		t.Fatalf("load test data error: %v", err)
+	}
+	if !model.IsMissing(samples[0].Attributes[0]) {
+		t.Fatalf("want missing value, got %v", samples[0].Attributes[0])
+	}
+	var m = dtree.NewModel(c45.Policy[T], dtree.NoPruning)
+	m.Train(samples, nil)
+	t.Logf("\n%v", m.Stringify(nil))
+	var root = m.Root()
+	if root.Weight != T(len(samples)) {
+		t.Fatalf("weight of root: want %d, got %v", len(samples), root.Weight)
+	}
+	if attr := root.GetChildByIndex(0).AttributeType; attr != 3 {
+		t.Fatalf("attribute of root: want 3, got %d", attr)
+	}
+	var correct int
+	for _, x := range samples {
+		if m.Predict(x.Attributes) == x.Label {
+			correct++
+		}
+	}
+	t.Logf("accuracy on training set: %d/%d", correct, len(samples))
+	var x = tensor.Vec(model.Missing[T](), model.Missing[T](), model.Missing[T](), 0, 2, 0)
+	if label := m.Predict(x); label != 1 {
+		t.Fatalf("predict %v: want 1, got %v", x, label)
+	}
+}
+
+func TestWeight(t *testing.T) {
+	type T = float64
+	dtree.TestWeight("../../testdata/watermelon/v3/data.csv", c45.Policy[T], t, dtree.WithContinuous[T](6, 7))
+}
+
+func TestEncoding(t *testing.T) {
+	type T = float32
+	dtree.TestEncoding("../../testdata/watermelon/v2alpha/data.csv", c45.Policy[T], t)
+}
@@ -5,22 +5,58 @@
 package cart
 
 import (
-	"github.com/gopherd/ml/model"
 	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/dtree"
+	"github.com/gopherd/ml/model"
 )
 
+// NewModel creates a binary classification tree which splits samples by the
+// best gini split point, i.e. x[attr] == v or x[attr] <= t for continuous attribute.
+func NewModel[T constraints.Float](pruningType dtree.PruningType, options ...dtree.Option[T]) *dtree.Model[T] {
+	return dtree.NewModel(Policy[T], pruningType, append([]dtree.Option[T]{
+		dtree.WithBinary[T](true),
+		dtree.WithAccumulator(dtree.NewGini[T]),
+	}, options...)...)
+}
+
+// NewRegressionModel creates a binary regression tree which splits samples by
+// the best variance split point.
+func NewRegressionModel[T constraints.Float](pruningType dtree.PruningType, options ...dtree.Option[T]) *dtree.Model[T] {
+	return dtree.NewModel(RegressionPolicy[T], pruningType, append([]dtree.Option[T]{
+		dtree.WithBinary[T](true),
+		dtree.WithRegression[T](true),
+	}, options...)...)
+}
+
+// Policy selects attribute which has maximum decrease of gini index, samples
+// are weighted by model.WeightOf and decrease is scaled by ratio of known weights.
 func Policy[T constraints.Float](samples []model.Sample[T], attrs []int) int {
-	var bestGini T
+	return policy(samples, attrs, model.WeightedGiniSet[[]model.Sample[T], T])
+}
+
+// RegressionPolicy selects attribute which has maximum decrease of variance
+// of labels, it's used to generate regression tree.
+func RegressionPolicy[T constraints.Float](samples []model.Sample[T], attrs []int) int {
+	return policy(samples, attrs, model.WeightedVariance[[]model.Sample[T], T])
+}
+
+func policy[T constraints.Float](samples []model.Sample[T], attrs []int, impurity func([]model.Sample[T]) T) int {
+	var bestGain T
 	var bestAttr = -1
-	var total = T(len(samples))
+	var total = model.SumWeights(samples)
 	for i, attr := range attrs {
-		var gini T = 1
-		for _, s := range model.Group(samples, attr) {
-			var p = T(len(s)) / total
-			gini -= p * p
+		var known = model.Known(samples, attr)
+		var knownTotal = model.SumWeights(known)
+		var gain T
+		if knownTotal > 0 {
+			gain = impurity(known)
+			for _, s := range model.Group(known, attr) {
+				gain -= model.SumWeights(s) / knownTotal * impurity(s)
+			}
+			gain *= knownTotal / total
 		}
-		if i == 0 || gini < bestGini {
-			bestGini = gini
+		if i == 0 || gain > bestGain {
+			bestGain = gain
 			bestAttr = i
 		}
 	}
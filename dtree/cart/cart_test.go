@@ -1,10 +1,16 @@
 package cart_test
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/doge/operator"
+	"github.com/gopherd/ml/dataloader"
 	"github.com/gopherd/ml/dtree"
 	"github.com/gopherd/ml/dtree/cart"
+	"github.com/gopherd/ml/model"
 )
 
 func TestModel(t *testing.T) {
@@ -13,3 +19,165 @@ func TestModel(t *testing.T) {
 	dtree.TestModel("../../testdata/watermelon/v2/data.csv", model, t)
 	t.Logf("\n%v", model.Stringify(nil))
 }
+
+func TestPostPruning(t *testing.T) {
+	type T = float32
+	dtree.TestPruning(cart.Policy[T], dtree.PostPruning, t)
+}
+
+func TestPrePruning(t *testing.T) {
+	type T = float32
+	dtree.TestPruning(cart.Policy[T], dtree.PrePruning, t,
+		dtree.WithValidationCheck[T](true),
+		dtree.WithMinSamplesLeaf[T](1),
+		dtree.WithImpurity(model.WeightedGiniSet[[]model.Sample[T]]),
+		dtree.WithMinGain[T](0.01),
+	)
+}
+
+func TestHoldout(t *testing.T) {
+	type T = float32
+	dtree.TestHoldout(cart.Policy[T], dtree.PostPruning, t)
+}
+
+func TestWeight(t *testing.T) {
+	type T = float64
+	dtree.TestWeight("../../testdata/watermelon/v3/data.csv", cart.Policy[T], t,
+		dtree.WithContinuous[T](6, 7),
+		dtree.WithImpurity(model.WeightedGiniSet[[]model.Sample[T]]),
+	)
+}
+
+func TestStoppingCriteria(t *testing.T) {
+	type T = float64
+	// labels of x are 0, 1, 0 on [0, 4), [4, 8), [8, 12)
+	var samples = make([]model.Sample[T], 12)
+	for i := range samples {
+		samples[i].Attributes = tensor.Vec(T(i))
+		samples[i].Label = T(i / 4 % 2)
+	}
+	var numNode = func(samples []model.Sample[T], options ...dtree.Option[T]) int {
+		var m = cart.NewModel(dtree.NoPruning, append(options, dtree.WithContinuous[T](0))...)
+		m.Train(samples, nil)
+		return m.NumNode()
+	}
+	if n := numNode(samples); n != 5 {
+		t.Fatalf("unlimited tree: got %d nodes, want 5", n)
+	}
+	// criteria apply without pre-pruning
+	if n := numNode(samples, dtree.WithMaxDepth[T](1)); n != 3 {
+		t.Fatalf("max depth 1: got %d nodes, want 3", n)
+	}
+	if n := numNode(samples, dtree.WithMinSamplesLeaf[T](4)); n != 5 {
+		t.Fatalf("min samples leaf 4: got %d nodes, want 5", n)
+	}
+	// leaves of 4 samples of weight 0.5 have less than 4 samples
+	for i := range samples {
+		samples[i].Weight = 0.5
+	}
+	if n := numNode(samples, dtree.WithMinSamplesLeaf[T](4)); n != 1 {
+		t.Fatalf("min samples leaf 4 of half weights: got %d nodes, want 1", n)
+	}
+}
+
+func TestRegression(t *testing.T) {
+	type T = float64
+	var samples = make([]model.Sample[T], 200)
+	for i := range samples {
+		x := T(i) / T(len(samples))
+		samples[i].Attributes = tensor.Vec(x, T(i%2))
+		samples[i].Label = T(math.Sin(2*math.Pi*float64(x))) + T(i%2)
+	}
+	var m = dtree.NewModel(cart.RegressionPolicy[T], dtree.PrePruning,
+		dtree.WithRegression[T](true),
+		dtree.WithContinuous[T](0),
+		dtree.WithMaxDepth[T](6),
+	)
+	m.Train(samples, nil)
+	var mse T
+	for _, x := range samples {
+		d := m.Predict(x.Attributes) - x.Label
+		mse += d * d
+	}
+	mse /= T(len(samples))
+	t.Logf("nodes: %d, mse: %v", m.NumNode(), mse)
+	if mse > 0.01 {
+		t.Fatalf("mse too large: %v", mse)
+	}
+}
+
+func TestBinary(t *testing.T) {
+	type T = float64
+	samples, err := dataloader.LoadCSVFile[T]("../../testdata/watermelon/v3/data.csv")
+	if err != nil {
+		t.Fatalf("load test data error: %v", err)
+	}
+	var m = cart.NewModel(dtree.NoPruning, dtree.WithContinuous[T](6, 7))
+	m.Train(samples, nil)
+	t.Logf("\n%v", m.Stringify(nil))
+	var check func(node *dtree.Node[T])
+	check = func(node *dtree.Node[T]) {
+		if n := node.NumChild(); n != 0 && n != 2 {
+			t.Fatalf("node %v has %d children", node, n)
+		}
+		for i := 0; i < node.NumChild(); i++ {
+			check(node.GetChildByIndex(i))
+		}
+	}
+	check(m.Root())
+	for i, x := range samples {
+		if label := m.Predict(x.Attributes); label != x.Label {
+			t.Fatalf("%dth: want %v, got %v", i, x.Label, label)
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	type T = float64
+	dtree.TestEncoding("../../testdata/watermelon/v3/data.csv", cart.Policy[T], t,
+		dtree.WithBinary[T](true),
+		dtree.WithContinuous[T](6, 7),
+		dtree.WithImpurity(model.WeightedGiniSet[[]model.Sample[T]]),
+	)
+}
+
+func TestAccumulator(t *testing.T) {
+	type T = float64
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]model.Sample[T], 500)
+	for i := range samples {
+		x, y := r.Float64(), T(r.Intn(4))
+		samples[i].Attributes = tensor.Vec(x, y)
+		samples[i].Label = T(int(x*3+y) % 3)
+		samples[i].Weight = r.Float64() + 0.5
+	}
+	for _, tc := range []struct {
+		name        string
+		accumulator func() dtree.Accumulator[T]
+		impurity    dtree.ImpurityFunc[T]
+	}{
+		{"entropy", dtree.NewEntropy[T], model.WeightedSumEntropySet[[]model.Sample[T]]},
+		{"gini", dtree.NewGini[T], model.WeightedGiniSet[[]model.Sample[T]]},
+		{"variance", dtree.NewVariance[T], model.WeightedVariance[[]model.Sample[T]]},
+	} {
+		var got, want = dtree.ImpurityOf(tc.accumulator)(samples), tc.impurity(samples)
+		if math.Abs(got-want) > 1e-9 {
+			t.Fatalf("%s: got impurity %v, want %v", tc.name, got, want)
+		}
+		// trees searching thresholds incrementally are the same as by impurity function
+		var options = []dtree.Option[T]{
+			dtree.WithBinary[T](true),
+			dtree.WithContinuous[T](0),
+			dtree.WithMaxDepth[T](4),
+			dtree.WithRegression[T](tc.name == "variance"),
+		}
+		var policy = operator.If(tc.name == "variance", cart.RegressionPolicy[T], cart.Policy[T])
+		var m1 = dtree.NewModel(policy, dtree.PrePruning, append(options, dtree.WithAccumulator(tc.accumulator))...)
+		var m2 = dtree.NewModel(policy, dtree.PrePruning, append(options, dtree.WithImpurity(tc.impurity))...)
+		m1.Train(samples, nil)
+		m2.Train(samples, nil)
+		if s1, s2 := m1.Stringify(nil), m2.Stringify(nil); s1 != s2 {
+			t.Fatalf("%s: tree by accumulator:\n%s\nwant:\n%s", tc.name, s1, s2)
+		}
+	}
+}
@@ -2,24 +2,61 @@ package dtree
 
 import (
 	"fmt"
+	"math/rand"
+	"sort"
 
 	"github.com/gopherd/doge/constraints"
-	"github.com/gopherd/doge/container/maps"
 	"github.com/gopherd/doge/container/ordered"
 	"github.com/gopherd/doge/container/slices"
 	"github.com/gopherd/doge/container/tree"
 	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/doge/operator"
 	"github.com/gopherd/ml/model"
 )
 
+// Operator represents comparison between attribute of sample and AttributeValue of node
+type Operator int
+
+const (
+	Equal     Operator = iota // x[AttributeType] == AttributeValue
+	NotEqual                  // x[AttributeType] != AttributeValue
+	LessEqual                 // x[AttributeType] <= AttributeValue
+	Greater                   // x[AttributeType] > AttributeValue
+)
+
+// String returns symbol of the operator
+func (op Operator) String() string {
+	switch op {
+	case NotEqual:
+		return "!="
+	case LessEqual:
+		return "<="
+	case Greater:
+		return ">"
+	default:
+		return "="
+	}
+}
+
+// Names names attributes, values and labels for printing nodes, e.g.
+// dataloader.Encoding. Empty name means unnamed.
+type Names interface {
+	AttributeName(attr int) string
+	ValueName(attr int, value float64) string
+	LabelName(label float64) string
+}
+
 // Node represents a node of decision tree
-type Node[T any] struct {
+type Node[T constraints.Float] struct {
 	parent   *Node[T]
 	children []*Node[T]
+	names    Names
 
-	AttributeType  int // attribute for spliting children, valid iff len(children) > 0
-	AttributeValue T   // value of attribute
-	Label          T   // class of sample
+	AttributeType  int      // attribute for spliting children, valid iff len(children) > 0
+	Operator       Operator // comparison between attribute of sample and AttributeValue
+	AttributeValue T        // value of attribute, or threshold of continuous attribute
+	Label          T        // class of sample
+	Weight         T        // sum of weights of training samples fell into the node
 }
 
 // String implements container.Node String method
@@ -27,7 +64,24 @@ func (node *Node[T]) String() string {
 	if node.parent == nil {
 		return "."
 	}
-	return fmt.Sprintf("attr[%d=%v]:%v", node.AttributeType, node.AttributeValue, node.Label)
+	if node.names == nil {
+		return fmt.Sprintf("attr[%d%v%v]:%v", node.AttributeType, node.Operator, node.AttributeValue, node.Label)
+	}
+	var attr = node.names.AttributeName(node.AttributeType)
+	if attr == "" {
+		attr = fmt.Sprintf("attr[%d]", node.AttributeType)
+	}
+	var value = fmt.Sprint(node.AttributeValue)
+	if node.Operator == Equal || node.Operator == NotEqual {
+		if name := node.names.ValueName(node.AttributeType, float64(node.AttributeValue)); name != "" {
+			value = name
+		}
+	}
+	var label = node.names.LabelName(float64(node.Label))
+	if label == "" {
+		label = fmt.Sprint(node.Label)
+	}
+	return fmt.Sprintf("%s%v%s:%s", attr, node.Operator, value, label)
 }
 
 // SetParent sets parent node
@@ -56,9 +110,70 @@ func (node *Node[T]) GetChildByIndex(i int) *Node[T] {
 	return node.children[i]
 }
 
+// test reports whether x satisfies condition of the node
+func (node *Node[T]) test(x tensor.Vector[T]) bool {
+	var v = x[node.AttributeType]
+	switch node.Operator {
+	case NotEqual:
+		return v != node.AttributeValue
+	case LessEqual:
+		return v <= node.AttributeValue
+	case Greater:
+		return v > node.AttributeValue
+	default:
+		return v == node.AttributeValue
+	}
+}
+
+// match returns index of child which x belongs to, or -1 if not found
+func (node *Node[T]) match(x tensor.Vector[T]) int {
+	for i, child := range node.children {
+		if child.test(x) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ratios returns ratio of training samples fell into each child
+func (node *Node[T]) ratios() []T {
+	var ratios = make([]T, len(node.children))
+	var total T
+	for i, child := range node.children {
+		ratios[i] = child.Weight
+		total += child.Weight
+	}
+	if total > 0 {
+		for i := range ratios {
+			ratios[i] /= total
+		}
+	}
+	return ratios
+}
+
+// setNames sets names of the subtree for printing
+func (node *Node[T]) setNames(names Names) {
+	node.names = names
+	for _, child := range node.children {
+		child.setNames(names)
+	}
+}
+
+// count returns number of nodes in the subtree
+func (node *Node[T]) count() int {
+	var n = 1
+	for _, child := range node.children {
+		n += child.count()
+	}
+	return n
+}
+
 // PolicyFunc used to lookup best attribute for spliting
 type PolicyFunc[T constraints.Float] func(samples []model.Sample[T], attrs []int) int
 
+// ImpurityFunc computes impurity of samples, e.g. information entropy or gini index
+type ImpurityFunc[T constraints.Float] func(samples []model.Sample[T]) T
+
 // PruningType represents type of pruning tree
 type PruningType int
 
@@ -68,18 +183,216 @@ const (
 	PostPruning
 )
 
-// Model implements brain.Model
+const defaultValidationRatio = 0.2
+
+// DefaultSeed is the default seed of random source of model
+const DefaultSeed = 1
+
+type options[T constraints.Float] struct {
+	validation      []model.Sample[T]
+	validationRatio T
+	seed            int64
+	impurity        ImpurityFunc[T]
+	accumulator     func() Accumulator[T]
+	continuous      map[int]bool
+	regression      bool
+	binary          bool
+	randomSubspace  bool
+
+	// stopping criteria, validationCheck is only for pre-pruning
+	maxDepth        int
+	minSamplesLeaf  int
+	minGain         T
+	validationCheck bool
+
+	names Names
+}
+
+func defaultOptions[T constraints.Float]() options[T] {
+	return options[T]{
+		validationRatio: defaultValidationRatio,
+		seed:            DefaultSeed,
+	}
+}
+
+// Option represents an option of decision tree model
+type Option[T constraints.Float] func(opt *options[T])
+
+func (opt *options[T]) apply(options []Option[T]) {
+	for _, o := range options {
+		o(opt)
+	}
+}
+
+// WithValidation sets validation set used for pruning
+func WithValidation[T constraints.Float](samples []model.Sample[T]) Option[T] {
+	return func(opt *options[T]) {
+		opt.validation = samples
+	}
+}
+
+// WithValidationRatio sets ratio of training samples held out as validation set
+// for pruning if validation set not specified, default is 0.2
+func WithValidationRatio[T constraints.Float](ratio T) Option[T] {
+	return func(opt *options[T]) {
+		opt.validationRatio = ratio
+	}
+}
+
+// WithSeed sets seed of random source used to hold out validation set and
+// select random attributes, default is DefaultSeed
+func WithSeed[T constraints.Float](seed int64) Option[T] {
+	return func(opt *options[T]) {
+		opt.seed = seed
+	}
+}
+
+// WithImpurity sets impurity function used to measure gain of splitting,
+// default is information entropy of weighted samples for classification tree
+// and variance for regression tree. Searching threshold of continuous
+// attribute by impurity function takes quadratic time, use WithAccumulator
+// if impurity can be computed incrementally.
+func WithImpurity[T constraints.Float](impurity ImpurityFunc[T]) Option[T] {
+	return func(opt *options[T]) {
+		opt.impurity = impurity
+		opt.accumulator = nil
+	}
+}
+
+// WithAccumulator sets impurity used to measure gain of splitting by
+// accumulator created by newAccumulator, e.g. NewGini[T]
+func WithAccumulator[T constraints.Float](newAccumulator func() Accumulator[T]) Option[T] {
+	return func(opt *options[T]) {
+		opt.impurity = ImpurityOf(newAccumulator)
+		opt.accumulator = newAccumulator
+	}
+}
+
+// WithRegression sets whether the tree is a regression tree, leaf of regression
+// tree predicts mean of labels instead of majority class.
+func WithRegression[T constraints.Float](yes bool) Option[T] {
+	return func(opt *options[T]) {
+		opt.regression = yes
+	}
+}
+
+// WithBinary sets whether the tree is a binary tree. Discrete attribute of
+// binary tree is split into two parts by the best value v: x[attr] == v and
+// x[attr] != v, so that every internal node has exactly two children.
+func WithBinary[T constraints.Float](yes bool) Option[T] {
+	return func(opt *options[T]) {
+		opt.binary = yes
+	}
+}
+
+// WithRandomSubspace sets whether each splitting selects attribute from a
+// random subset of log₂(k) attributes (at least one) of k candidates, which
+// are drawn from random source seeded by WithSeed. It's usually used by
+// trees of random forest.
+func WithRandomSubspace[T constraints.Float](yes bool) Option[T] {
+	return func(opt *options[T]) {
+		opt.randomSubspace = yes
+	}
+}
+
+// WithContinuous marks attributes as continuous, continuous attribute is
+// split into two parts by threshold: x[attr] <= t and x[attr] > t.
+func WithContinuous[T constraints.Float](attrs ...int) Option[T] {
+	return func(opt *options[T]) {
+		if opt.continuous == nil {
+			opt.continuous = make(map[int]bool)
+		}
+		for _, attr := range attrs {
+			opt.continuous[attr] = true
+		}
+	}
+}
+
+// WithMaxDepth sets maximum depth of tree, 0 means unlimited. It applies to
+// any pruning type.
+func WithMaxDepth[T constraints.Float](depth int) Option[T] {
+	return func(opt *options[T]) {
+		opt.maxDepth = depth
+	}
+}
+
+// WithMinSamplesLeaf sets minimum number of samples in each leaf, samples are
+// counted by their weights. It applies to any pruning type.
+func WithMinSamplesLeaf[T constraints.Float](n int) Option[T] {
+	return func(opt *options[T]) {
+		opt.minSamplesLeaf = n
+	}
+}
+
+// WithMinGain sets minimum decrease of impurity required by splitting. It
+// applies to any pruning type.
+func WithMinGain[T constraints.Float](gain T) Option[T] {
+	return func(opt *options[T]) {
+		opt.minGain = gain
+	}
+}
+
+// WithValidationCheck sets whether to check accuracy on validation set
+// before each splitting for pre-pruning
+func WithValidationCheck[T constraints.Float](yes bool) Option[T] {
+	return func(opt *options[T]) {
+		opt.validationCheck = yes
+	}
+}
+
+// WithNames sets names for printing nodes, e.g. encoding returned by
+// dataloader.LoadCSVEncoded
+func WithNames[T constraints.Float](names Names) Option[T] {
+	return func(opt *options[T]) {
+		opt.names = names
+	}
+}
+
+var _ model.Model[float64] = (*Model[float64])(nil)
+
+// Model implements model.Model
 type Model[T constraints.Float] struct {
 	policy      PolicyFunc[T]
 	pruningType PruningType
+	options     options[T]
 	root        *Node[T]
+	rand        *rand.Rand // random source of training
 }
 
-func NewModel[T constraints.Float](policy PolicyFunc[T], pruningType PruningType) *Model[T] {
-	return &Model[T]{
+func NewModel[T constraints.Float](policy PolicyFunc[T], pruningType PruningType, options ...Option[T]) *Model[T] {
+	var m = &Model[T]{
 		policy:      policy,
 		pruningType: pruningType,
+		options:     defaultOptions[T](),
+	}
+	m.options.apply(options)
+	if m.options.impurity == nil {
+		if m.options.regression {
+			m.options.accumulator = NewVariance[T]
+		} else {
+			m.options.accumulator = NewEntropy[T]
+		}
+		m.options.impurity = ImpurityOf(m.options.accumulator)
 	}
+	return m
+}
+
+// Regression reports whether the model is a regression tree
+func (m *Model[T]) Regression() bool {
+	return m.options.regression
+}
+
+// Root returns root node of the tree
+func (m *Model[T]) Root() *Node[T] {
+	return m.root
+}
+
+// NumNode returns number of nodes of the tree
+func (m *Model[T]) NumNode() int {
+	if m.root == nil {
+		return 0
+	}
+	return m.root.count()
 }
 
 // Stringify format the tree to string
@@ -87,31 +400,88 @@ func (m *Model[T]) Stringify(options *tree.Options) string {
 	return tree.Stringify[*Node[T]](m.root, options)
 }
 
-// Train trains the decision tree
-func (m *Model[T]) Train(samples []model.Sample[T]) {
+// Train trains the decision tree. If the model is post-pruning (or pre-pruning
+// with validation check) and no validation set specified, a part of samples
+// will be held out as validation set.
+func (m *Model[T]) Train(samples []model.Sample[T], tracker model.Tracker) {
 	m.root = new(Node[T])
 	if len(samples) == 0 {
 		return
 	}
+	m.rand = rand.New(rand.NewSource(m.options.seed))
+	var validation = m.options.validation
+	if len(validation) == 0 && m.needsValidation() {
+		samples, validation = holdout(samples, m.options.validationRatio, m.rand)
+	}
 	var n = len(samples[0].Attributes)
 	var attrs = tensor.RangeN(n)
 	var attrValues = make([]*ordered.Map[T, int], len(attrs))
 	for i := 0; i < n; i++ {
+		if m.options.continuous[i] {
+			continue
+		}
 		attrValues[i] = ordered.NewMap[T, int]()
 		for _, x := range samples {
 			var k = x.Attributes[i]
-			attrValues[i].Insert(k, attrValues[i].Get(k)+1)
+			if !model.IsMissing(k) {
+				attrValues[i].Insert(k, attrValues[i].Get(k)+1)
+			}
+		}
+	}
+	m.generateChildren(m.root, samples, validation, attrValues, attrs, 0)
+	if m.pruningType == PostPruning && len(validation) > 0 {
+		m.postPruning(m.root, validation)
+	}
+	m.SetNames(m.options.names)
+}
+
+// SetSeed sets seed of random source like WithSeed, e.g. random forest sets
+// different seeds for its trees
+func (m *Model[T]) SetSeed(seed int64) {
+	m.options.seed = seed
+}
+
+// SetNames sets names for printing nodes
+func (m *Model[T]) SetNames(names Names) {
+	m.options.names = names
+	if m.root != nil {
+		m.root.setNames(names)
+	}
+}
+
+func (m *Model[T]) needsValidation() bool {
+	return m.pruningType == PostPruning || (m.pruningType == PrePruning && m.options.validationCheck)
+}
+
+// holdout splits samples into training set and validation set randomly
+func holdout[T constraints.Float](samples []model.Sample[T], ratio T, r *rand.Rand) (train, validation []model.Sample[T]) {
+	var n = int(T(len(samples)) * ratio)
+	if n < 1 || n >= len(samples) {
+		return samples, nil
+	}
+	var indices = r.Perm(len(samples))
+	train = make([]model.Sample[T], 0, len(samples)-n)
+	validation = make([]model.Sample[T], 0, n)
+	for i, j := range indices {
+		if i < n {
+			validation = append(validation, samples[j])
+		} else {
+			train = append(train, samples[j])
 		}
 	}
-	m.generateChildren(m.root, samples, attrValues, attrs)
+	return
 }
 
 func (m *Model[T]) generateChildren(
 	parent *Node[T],
 	samples []model.Sample[T],
+	validation []model.Sample[T],
 	attributeValues []*ordered.Map[T, int],
 	attributeTypes []int,
+	depth int,
 ) {
+	parent.Weight = model.SumWeights(samples)
+
 	// are all classes same?
 	var allSame = true
 	for i := range samples {
@@ -128,51 +498,402 @@ func (m *Model[T]) generateChildren(
 	// are all values same on attrs?
 	allSame = true
 	for _, attr := range attributeTypes {
-		var sameAttr = true
-		for j := range samples {
-			if j > 0 && samples[j].Attributes[attr] != samples[j-1].Attributes[attr] {
-				sameAttr = false
-				break
-			}
-		}
-		if !sameAttr {
+		if !sameValues(samples, attr) {
 			allSame = false
 			break
 		}
 	}
+	parent.Label = m.leafValue(samples)
 	if len(attributeTypes) == 0 || allSame {
-		parent.Label = maps.MaxValue(model.Counters(samples)).First
+		return
+	}
+	if m.options.maxDepth > 0 && depth >= m.options.maxDepth {
 		return
 	}
 
 	// lookup best attribute for splitting
-	var best = m.policy(samples, attributeTypes)
-	var bestAttr = attributeTypes[best]
-	var last = len(attributeTypes) - 1
-	if best != last {
-		attributeTypes[best] = attributeTypes[last]
-	}
-	attributeTypes = attributeTypes[:last]
-	var groups = model.Group(samples, bestAttr)
-	var iter = attributeValues[bestAttr].First()
-	for iter != nil {
-		var attrValue = iter.Key()
-		iter = iter.Next()
-		var node = new(Node[T])
-		node.AttributeType = bestAttr
-		node.AttributeValue = attrValue
+	var children = m.split(samples, attributeValues, attributeTypes)
+	if len(children) == 0 {
+		return
+	}
+	var groups, ratios = partition(children, samples, nil)
+	var validationGroups, _ = partition(children, validation, ratios)
+	if !m.shouldSplit(parent, samples, groups, validationGroups) {
+		return
+	}
+	// attribute split into two parts could be used again by descendants
+	if last := children[len(children)-1]; last.Operator == Equal {
+		attributeTypes = remove(attributeTypes, last.AttributeType)
+	}
+	for i, node := range children {
 		parent.AddChild(node)
-		if s, ok := groups[attrValue]; ok {
-			m.generateChildren(node, s, attributeValues, attributeTypes)
+		if len(groups[i]) > 0 {
+			m.generateChildren(node, groups[i], validationGroups[i], attributeValues, attributeTypes, depth+1)
 		} else {
-			node.Label = maps.MaxValue(model.Counters(samples)).First
+			node.Label = parent.Label
 		}
 	}
 }
 
-// postPruning post-pruning decision tree
-func (m *Model[T]) postPruning(root *Node[T], samples []model.Sample[T]) {
-	panic("TODO")
+// sameValues reports whether all known values of attribute are same
+func sameValues[T constraints.Float](samples []model.Sample[T], attr int) bool {
+	var first = true
+	var value T
+	for i := range samples {
+		var v = samples[i].Attributes[attr]
+		if model.IsMissing(v) {
+			continue
+		}
+		if first {
+			value = v
+			first = false
+		} else if v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// split selects best attribute by policy and creates children nodes for the
+// attribute. Continuous attribute (and discrete attribute of binary tree) is
+// partitioned into two parts by its best condition, and policy sees it as a
+// binary attribute: 0 if the condition is satisfied, or 1 otherwise.
+func (m *Model[T]) split(
+	samples []model.Sample[T],
+	attributeValues []*ordered.Map[T, int],
+	attributeTypes []int,
+) []*Node[T] {
+	var attrs = make([]int, 0, len(attributeTypes))
+	var conditions = make(map[int]*Node[T])
+	for _, attr := range attributeTypes {
+		if m.options.continuous[attr] {
+			if t, ok := m.bestThreshold(samples, attr); ok {
+				attrs = append(attrs, attr)
+				conditions[attr] = &Node[T]{AttributeType: attr, Operator: LessEqual, AttributeValue: t}
+			}
+		} else if m.options.binary {
+			if v, ok := m.bestValue(samples, attr); ok {
+				attrs = append(attrs, attr)
+				conditions[attr] = &Node[T]{AttributeType: attr, Operator: Equal, AttributeValue: v}
+			}
+		} else if len(model.Known(samples, attr)) > 0 {
+			attrs = append(attrs, attr)
+		}
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	if m.options.randomSubspace {
+		var n = model.Log2(uint(len(attrs)))
+		if n < 1 {
+			n = 1
+		}
+		m.rand.Shuffle(len(attrs), func(i, j int) {
+			attrs[i], attrs[j] = attrs[j], attrs[i]
+		})
+		attrs = attrs[:n]
+	}
+	var view = samples
+	if len(conditions) > 0 {
+		view = make([]model.Sample[T], len(samples))
+		for i := range samples {
+			view[i] = samples[i]
+			view[i].Attributes = slices.Clone(samples[i].Attributes)
+			for attr, cond := range conditions {
+				if !model.IsMissing(samples[i].Attributes[attr]) {
+					view[i].Attributes[attr] = operator.If[T](cond.test(samples[i].Attributes), 0, 1)
+				}
+			}
+		}
+	}
+	var attr = attrs[m.policy(view, attrs)]
+	if cond, ok := conditions[attr]; ok {
+		var other = *cond
+		other.Operator = operator.If(cond.Operator == Equal, NotEqual, Greater)
+		return []*Node[T]{cond, &other}
+	}
+	var children []*Node[T]
+	for iter := attributeValues[attr].First(); iter != nil; iter = iter.Next() {
+		children = append(children, &Node[T]{AttributeType: attr, AttributeValue: iter.Key()})
+	}
+	return children
+}
+
+// bestThreshold finds best threshold of continuous attribute by bi-partition:
+// midpoints of adjacent known values are tried and the one which minimizes
+// impurity of the two parts is selected.
+func (m *Model[T]) bestThreshold(samples []model.Sample[T], attr int) (threshold T, ok bool) {
+	var sorted = model.Known(samples, attr)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Attributes[attr] < sorted[j].Attributes[attr]
+	})
+	var total = model.SumWeights(sorted)
+	// impurity computes impurity of parts sorted[:i] and sorted[i:], i increases
+	var impurity func(i int, leftTotal T) T
+	if newAccumulator := m.options.accumulator; newAccumulator != nil {
+		var left, right = newAccumulator(), newAccumulator()
+		for _, x := range sorted {
+			right.Add(x.Label, model.WeightOf(x))
+		}
+		var next int
+		impurity = func(i int, leftTotal T) T {
+			for ; next < i; next++ {
+				var x = sorted[next]
+				var w = model.WeightOf(x)
+				left.Add(x.Label, w)
+				right.Remove(x.Label, w)
+			}
+			return (leftTotal*left.Impurity() + (total-leftTotal)*right.Impurity()) / total
+		}
+	} else {
+		impurity = func(i int, leftTotal T) T {
+			return (leftTotal*m.options.impurity(sorted[:i]) + (total-leftTotal)*m.options.impurity(sorted[i:])) / total
+		}
+	}
+	var leftTotal T
+	var min T
+	for i := 1; i < len(sorted); i++ {
+		leftTotal += model.WeightOf(sorted[i-1])
+		var prev, curr = sorted[i-1].Attributes[attr], sorted[i].Attributes[attr]
+		if prev == curr {
+			continue
+		}
+		if impurity := impurity(i, leftTotal); !ok || impurity < min {
+			min = impurity
+			threshold = (prev + curr) / 2
+			ok = true
+		}
+	}
+	return
+}
+
+// bestValue finds best value v of discrete attribute for binary partition:
+// x[attr] == v and x[attr] != v, the one which minimizes impurity of the two
+// parts is selected.
+func (m *Model[T]) bestValue(samples []model.Sample[T], attr int) (value T, ok bool) {
+	var known = model.Known(samples, attr)
+	var groups = model.Group(known, attr)
+	if len(groups) < 2 {
+		return
+	}
+	var total = model.SumWeights(known)
+	var values = make([]T, 0, len(groups))
+	for v := range groups {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i] < values[j]
+	})
+	// impurity computes impurity of parts group and known - group
+	var impurity func(group []model.Sample[T]) T
+	if newAccumulator := m.options.accumulator; newAccumulator != nil {
+		var rest = newAccumulator()
+		for _, x := range known {
+			rest.Add(x.Label, model.WeightOf(x))
+		}
+		impurity = func(group []model.Sample[T]) T {
+			var a = newAccumulator()
+			var w T
+			for _, x := range group {
+				a.Add(x.Label, model.WeightOf(x))
+				rest.Remove(x.Label, model.WeightOf(x))
+				w += model.WeightOf(x)
+			}
+			var impurity = (w*a.Impurity() + (total-w)*rest.Impurity()) / total
+			for _, x := range group {
+				rest.Add(x.Label, model.WeightOf(x))
+			}
+			return impurity
+		}
+	} else {
+		var rest = make([]model.Sample[T], 0, len(known))
+		impurity = func(group []model.Sample[T]) T {
+			var v = group[0].Attributes[attr]
+			rest = rest[:0]
+			for _, x := range known {
+				if x.Attributes[attr] != v {
+					rest = append(rest, x)
+				}
+			}
+			var w = model.SumWeights(group)
+			return (w*m.options.impurity(group) + (total-w)*m.options.impurity(rest)) / total
+		}
+	}
+	var min T
+	for _, v := range values {
+		if impurity := impurity(groups[v]); !ok || impurity < min {
+			min = impurity
+			value = v
+			ok = true
+		}
+	}
+	return
+}
+
+// partition groups samples by conditions of children. Samples missing the
+// attribute are put into all children with weights scaled by ratios, ratios
+// are computed by weights of known samples if it's nil.
+func partition[T constraints.Float](children []*Node[T], samples []model.Sample[T], ratios []T) ([][]model.Sample[T], []T) {
+	var attr = children[0].AttributeType
+	var groups = make([][]model.Sample[T], len(children))
+	var missing []model.Sample[T]
+	for _, x := range samples {
+		if model.IsMissing(x.Attributes[attr]) {
+			missing = append(missing, x)
+			continue
+		}
+		for i, child := range children {
+			if child.test(x.Attributes) {
+				groups[i] = append(groups[i], x)
+				break
+			}
+		}
+	}
+	if ratios == nil {
+		ratios = make([]T, len(children))
+		var total T
+		for i := range groups {
+			ratios[i] = model.SumWeights(groups[i])
+			total += ratios[i]
+		}
+		if total > 0 {
+			for i := range ratios {
+				ratios[i] /= total
+			}
+		}
+	}
+	for _, x := range missing {
+		for i, r := range ratios {
+			if r > 0 {
+				var y = x
+				y.Weight = model.WeightOf(x) * r
+				groups[i] = append(groups[i], y)
+			}
+		}
+	}
+	return groups, ratios
+}
+
+// remove returns a copy of attrs without attr
+func remove(attrs []int, attr int) []int {
+	var result = make([]int, 0, len(attrs))
+	for _, a := range attrs {
+		if a != attr {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// shouldSplit reports whether the parent node should be split into groups
+// by checking stopping criteria
+func (m *Model[T]) shouldSplit(
+	parent *Node[T],
+	samples []model.Sample[T],
+	groups [][]model.Sample[T],
+	validationGroups [][]model.Sample[T],
+) bool {
+	if m.options.minSamplesLeaf > 0 {
+		for _, s := range groups {
+			if len(s) > 0 && model.SumWeights(s) < T(m.options.minSamplesLeaf) {
+				return false
+			}
+		}
+	}
+	if m.options.minGain > 0 {
+		var total = model.SumWeights(samples)
+		var gain = m.options.impurity(samples)
+		for _, s := range groups {
+			gain -= model.SumWeights(s) / total * m.options.impurity(s)
+		}
+		if gain < m.options.minGain {
+			return false
+		}
+	}
+	if m.pruningType == PrePruning && m.options.validationCheck {
+		// split only if it improves score on validation set
+		var n int
+		var leafScore, splitScore T
+		for i, s := range validationGroups {
+			var label = parent.Label
+			if len(groups[i]) > 0 {
+				label = m.leafValue(groups[i])
+			}
+			n += len(s)
+			leafScore += m.score(s, parent.Label)
+			splitScore += m.score(s, label)
+		}
+		if n > 0 && splitScore <= leafScore+model.Epsilon {
+			return false
+		}
+	}
+	return true
+}
+
+// leafValue returns the value predicted by leaf which samples fell into:
+// mean of labels for regression tree, or majority class for classification tree.
+func (m *Model[T]) leafValue(samples []model.Sample[T]) T {
+	if m.options.regression {
+		return model.WeightedMean(samples)
+	}
+	return majority(model.WeightedCounters(samples))
+}
+
+// majority returns the label of maximum weight, the smaller label wins if
+// tied so that the result doesn't depend on order of map iteration
+func majority[T constraints.Float](weights map[T]T) T {
+	var best, bestWeight T
+	var first = true
+	for label, w := range weights {
+		if first || w > bestWeight || (w == bestWeight && label < best) {
+			best, bestWeight = label, w
+			first = false
+		}
+	}
+	return best
+}
+
+// score evaluates samples all predicted as label: weight of correctly predicted
+// samples for classification tree, or negative squared error for regression tree.
+func (m *Model[T]) score(samples []model.Sample[T], label T) T {
+	var score T
+	for _, x := range samples {
+		if m.options.regression {
+			var d = x.Label - label
+			score -= model.WeightOf(x) * d * d
+		} else if x.Label == label {
+			score += model.WeightOf(x)
+		}
+	}
+	return score
+}
+
+// postPruning prunes the subtree of node bottom-up by reduced-error pruning:
+// a subtree is replaced by a leaf labeled by its training samples whenever
+// that does not lower score on validation samples. It returns score of the
+// pruned subtree on validation samples.
+func (m *Model[T]) postPruning(node *Node[T], samples []model.Sample[T]) T {
+	var leafScore = m.score(samples, node.Label)
+	if len(node.children) == 0 {
+		return leafScore
+	}
+	var groups, _ = partition(node.children, samples, node.ratios())
+	var score T
+	for i, child := range node.children {
+		score += m.postPruning(child, groups[i])
+	}
+	// samples matching no child are predicted by label of the node
+	var attr = node.children[0].AttributeType
+	for _, x := range samples {
+		if !model.IsMissing(x.Attributes[attr]) && node.match(x.Attributes) < 0 {
+			score += m.score([]model.Sample[T]{x}, node.Label)
+		}
+	}
+	if leafScore+model.Epsilon >= score {
+		node.children = nil
+		return leafScore
+	}
+	return score
 }
 
 // Predict predicts label for sample
@@ -181,15 +902,50 @@ func (m *Model[T]) Predict(x tensor.Vector[T]) T {
 }
 
 func (m *Model[T]) predict(node *Node[T], x tensor.Vector[T]) T {
-	for _, child := range node.children {
-		if x[child.AttributeType] == child.AttributeValue {
-			return m.predict(child, x)
+	if len(node.children) > 0 && model.IsMissing(x[node.children[0].AttributeType]) {
+		var probs = make(map[T]T)
+		m.distribute(node, x, 1, probs)
+		if m.options.regression {
+			var sum T
+			for label, p := range probs {
+				sum += label * p
+			}
+			return sum
 		}
+		return majority(probs)
+	}
+	if i := node.match(x); i >= 0 {
+		return m.predict(node.children[i], x)
 	}
 	return node.Label
 }
 
-// RF wraps policy for random forest
+// distribute spreads x with weight w across all branches whose attribute is
+// missing and accumulates weights of labels reached into probs.
+func (m *Model[T]) distribute(node *Node[T], x tensor.Vector[T], w T, probs map[T]T) {
+	if len(node.children) == 0 {
+		probs[node.Label] += w
+		return
+	}
+	if !model.IsMissing(x[node.children[0].AttributeType]) {
+		if i := node.match(x); i >= 0 {
+			m.distribute(node.children[i], x, w, probs)
+		} else {
+			probs[node.Label] += w
+		}
+		return
+	}
+	var ratios = node.ratios()
+	for i, child := range node.children {
+		if ratios[i] > 0 {
+			m.distribute(child, x, w*ratios[i], probs)
+		}
+	}
+}
+
+// RF wraps policy for random forest, attributes are selected from a random
+// subset drawn from global random source, use WithRandomSubspace instead for
+// reproducible trees
 func RF[T constraints.Float](policy PolicyFunc[T]) PolicyFunc[T] {
 	return func(samples []model.Sample[T], attrs []int) int {
 		var n = model.Log2(uint(len(attrs)))
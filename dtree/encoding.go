@@ -0,0 +1,100 @@
+package dtree
+
+import (
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/model"
+)
+
+// nodeState is the encoded form of Node
+type nodeState[T constraints.Float] struct {
+	AttributeType  int             `json:"attr"`
+	Operator       Operator        `json:"op,omitempty"`
+	AttributeValue T               `json:"value"`
+	Label          T               `json:"label"`
+	Weight         T               `json:"weight"`
+	Children       []*nodeState[T] `json:"children,omitempty"`
+}
+
+// modelState is the encoded form of Model
+type modelState[T constraints.Float] struct {
+	Regression bool          `json:"regression,omitempty"`
+	Root       *nodeState[T] `json:"root"`
+}
+
+func encodeNode[T constraints.Float](node *Node[T]) *nodeState[T] {
+	if node == nil {
+		return nil
+	}
+	var s = &nodeState[T]{
+		AttributeType:  node.AttributeType,
+		Operator:       node.Operator,
+		AttributeValue: node.AttributeValue,
+		Label:          node.Label,
+		Weight:         node.Weight,
+	}
+	for _, child := range node.children {
+		s.Children = append(s.Children, encodeNode(child))
+	}
+	return s
+}
+
+func decodeNode[T constraints.Float](s *nodeState[T]) *Node[T] {
+	if s == nil {
+		return nil
+	}
+	var node = &Node[T]{
+		AttributeType:  s.AttributeType,
+		Operator:       s.Operator,
+		AttributeValue: s.AttributeValue,
+		Label:          s.Label,
+		Weight:         s.Weight,
+	}
+	for _, child := range s.Children {
+		node.AddChild(decodeNode(child))
+	}
+	return node
+}
+
+func (m *Model[T]) state() modelState[T] {
+	return modelState[T]{
+		Regression: m.options.regression,
+		Root:       encodeNode(m.root),
+	}
+}
+
+func (m *Model[T]) setState(s modelState[T]) {
+	m.options.regression = s.Regression
+	m.root = decodeNode(s.Root)
+	m.SetNames(m.options.names)
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *Model[T]) MarshalJSON() ([]byte, error) {
+	return model.EncodeJSON(m.state())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Only the trained tree is decoded,
+// policy and options of m are kept for further training.
+func (m *Model[T]) UnmarshalJSON(data []byte) error {
+	var s modelState[T]
+	if err := model.DecodeJSON(data, &s); err != nil {
+		return err
+	}
+	m.setState(s)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (m *Model[T]) MarshalBinary() ([]byte, error) {
+	return model.EncodeBinary(m.state())
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (m *Model[T]) UnmarshalBinary(data []byte) error {
+	var s modelState[T]
+	if err := model.DecodeBinary(data, &s); err != nil {
+		return err
+	}
+	m.setState(s)
+	return nil
+}
@@ -6,21 +6,29 @@
 package id3
 
 import (
-	"github.com/gopherd/ml/model"
 	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/model"
 )
 
+// Policy selects attribute which has maximum information gain, samples are
+// weighted by model.WeightOf and gain is scaled by ratio of known weights.
 func Policy[T constraints.Float](samples []model.Sample[T], attrs []int) int {
 	var bestGain T
 	var bestAttr = -1
-	var total = T(len(samples))
+	var total = model.SumWeights(samples)
 	for i, attr := range attrs {
-		var sum T
-		for _, s := range model.Group(samples, attr) {
-			sum += T(len(s)) / total * model.SumEntropySet(s)
+		var known = model.Known(samples, attr)
+		var knownTotal = model.SumWeights(known)
+		var gain T
+		if knownTotal > 0 {
+			gain = model.WeightedSumEntropySet(known)
+			for _, s := range model.Group(known, attr) {
+				gain -= model.SumWeights(s) / knownTotal * model.WeightedSumEntropySet(s)
+			}
+			gain *= knownTotal / total
 		}
-		if i == 0 || sum < bestGain {
-			bestGain = sum
+		if i == 0 || gain > bestGain {
+			bestGain = gain
 			bestAttr = i
 		}
 	}
@@ -1,8 +1,11 @@
 package id3_test
 
 import (
+	"math"
+	"strings"
 	"testing"
 
+	"github.com/gopherd/ml/dataloader"
 	"github.com/gopherd/ml/dtree"
 	"github.com/gopherd/ml/dtree/id3"
 )
@@ -13,3 +16,69 @@ func TestModel(t *testing.T) {
 	dtree.TestModel("../../testdata/watermelon/v2/data.csv", model, t)
 	t.Logf("\n%v", model.Stringify(nil))
 }
+
+func TestPostPruning(t *testing.T) {
+	type T = float32
+	dtree.TestPruning(id3.Policy[T], dtree.PostPruning, t)
+}
+
+func TestPrePruning(t *testing.T) {
+	type T = float32
+	dtree.TestPruning(id3.Policy[T], dtree.PrePruning, t,
+		dtree.WithValidationCheck[T](true),
+		dtree.WithMinSamplesLeaf[T](1),
+		dtree.WithMinGain[T](0.01),
+	)
+}
+
+func TestHoldout(t *testing.T) {
+	type T = float32
+	dtree.TestHoldout(id3.Policy[T], dtree.PostPruning, t)
+}
+
+func TestContinuous(t *testing.T) {
+	type T = float64
+	samples, err := dataloader.LoadCSVFile[T]("../../testdata/watermelon/v3/data.csv")
+	if err != nil {
+		t.Fatalf("load test data error: %v", err)
+	}
+	var model = dtree.NewModel(id3.Policy[T], dtree.NoPruning, dtree.WithContinuous[T](6, 7))
+	model.Train(samples, nil)
+	t.Logf("\n%v", model.Stringify(nil))
+	// texture=clear => density <= 0.3815
+	var node = model.Root().GetChildByIndex(0).GetChildByIndex(0)
+	if node.AttributeType != 6 || node.Operator != dtree.LessEqual || math.Abs(node.AttributeValue-0.3815) > 1e-6 {
+		t.Fatalf("want attr[6<=0.3815], got %v", node)
+	}
+	for i, x := range samples {
+		if label := model.Predict(x.Attributes); label != x.Label {
+			t.Fatalf("%dth: want %v, got %v", i, x.Label, label)
+		}
+	}
+}
+
+func TestWeight(t *testing.T) {
+	type T = float64
+	dtree.TestWeight("../../testdata/watermelon/v3/data.csv", id3.Policy[T], t, dtree.WithContinuous[T](6, 7))
+}
+
+func TestNames(t *testing.T) {
+	type T = float64
+	samples, encoding, err := dataloader.LoadCSVFileEncoded[T]("../../testdata/watermelon/raw/data.csv",
+		dataloader.WithCSVLabel("好瓜"),
+		dataloader.WithCSVDrop("编号", "密度", "含糖率"),
+	)
+	if err != nil {
+		t.Fatalf("load test data error: %v", err)
+	}
+	if len(encoding.Features) != 6 || encoding.Features[3] != "纹理" || encoding.Target != "好瓜" {
+		t.Fatalf("unexpected features %v of target %q", encoding.Features, encoding.Target)
+	}
+	var model = dtree.NewModel(id3.Policy[T], dtree.NoPruning, dtree.WithNames[T](encoding))
+	model.Train(samples, nil)
+	var s = model.Stringify(nil)
+	t.Logf("\n%v", s)
+	if !strings.Contains(s, "纹理=清晰:") {
+		t.Fatalf("root should split by 纹理:\n%s", s)
+	}
+}
@@ -0,0 +1,156 @@
+package dtree
+
+import (
+	"math"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/model"
+)
+
+// Accumulator accumulates labels of weighted samples and computes impurity
+// of accumulated samples incrementally, it's used to sweep sorted samples
+// while searching threshold of continuous attribute in linear time.
+type Accumulator[T constraints.Float] interface {
+	// Add adds a sample which has label and weight
+	Add(label, weight T)
+	// Remove removes a sample added before
+	Remove(label, weight T)
+	// Impurity returns impurity of accumulated samples
+	Impurity() T
+}
+
+// ImpurityOf returns impurity function computed by accumulator
+func ImpurityOf[T constraints.Float](newAccumulator func() Accumulator[T]) ImpurityFunc[T] {
+	return func(samples []model.Sample[T]) T {
+		var a = newAccumulator()
+		for i := range samples {
+			a.Add(samples[i].Label, model.WeightOf(samples[i]))
+		}
+		return a.Impurity()
+	}
+}
+
+// xlogx computes x‧log₂(x), it's 0 if x is not positive
+func xlogx[T constraints.Float](x T) T {
+	if x <= 0 {
+		return 0
+	}
+	return x * T(math.Log2(float64(x)))
+}
+
+type entropy[T constraints.Float] struct {
+	counters map[T]T
+	total    T
+	sum      T // Σₖ(cₖ‧log₂(cₖ))
+}
+
+// NewEntropy creates an accumulator of information entropy:
+//
+//	H = -Σₖ(pₖ‧log₂(pₖ)) = log₂(c) - Σₖ(cₖ‧log₂(cₖ))/c
+//
+// where cₖ is weight of class k and c = Σₖcₖ
+func NewEntropy[T constraints.Float]() Accumulator[T] {
+	return &entropy[T]{counters: make(map[T]T)}
+}
+
+func (e *entropy[T]) update(label, weight T) {
+	var c = e.counters[label]
+	e.sum -= xlogx(c)
+	c += weight
+	if c <= model.Epsilon {
+		c = 0
+	}
+	e.counters[label] = c
+	e.sum += xlogx(c)
+	e.total += weight
+}
+
+func (e *entropy[T]) Add(label, weight T)    { e.update(label, weight) }
+func (e *entropy[T]) Remove(label, weight T) { e.update(label, -weight) }
+
+func (e *entropy[T]) Impurity() T {
+	if e.total <= model.Epsilon {
+		return 0
+	}
+	var h = xlogx(e.total)/e.total - e.sum/e.total
+	if h < 0 {
+		return 0
+	}
+	return h
+}
+
+type gini[T constraints.Float] struct {
+	counters map[T]T
+	total    T
+	squares  T // Σₖ(cₖ²)
+}
+
+// NewGini creates an accumulator of gini index:
+//
+//	g = 1 - Σₖ(pₖ²) = 1 - Σₖ(cₖ²)/c²
+//
+// where cₖ is weight of class k and c = Σₖcₖ
+func NewGini[T constraints.Float]() Accumulator[T] {
+	return &gini[T]{counters: make(map[T]T)}
+}
+
+func (g *gini[T]) update(label, weight T) {
+	var c = g.counters[label]
+	g.squares -= c * c
+	c += weight
+	if c <= model.Epsilon {
+		c = 0
+	}
+	g.counters[label] = c
+	g.squares += c * c
+	g.total += weight
+}
+
+func (g *gini[T]) Add(label, weight T)    { g.update(label, weight) }
+func (g *gini[T]) Remove(label, weight T) { g.update(label, -weight) }
+
+func (g *gini[T]) Impurity() T {
+	if g.total <= model.Epsilon {
+		return 0
+	}
+	var v = 1 - g.squares/(g.total*g.total)
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
+type variance[T constraints.Float] struct {
+	total, sum, squares T
+}
+
+// NewVariance creates an accumulator of variance of labels:
+//
+//	σ² = Σᵢ(wᵢ‧yᵢ²)/w - (Σᵢ(wᵢ‧yᵢ)/w)²
+//
+// where w = Σᵢwᵢ
+func NewVariance[T constraints.Float]() Accumulator[T] {
+	return &variance[T]{}
+}
+
+func (v *variance[T]) Add(label, weight T) {
+	v.total += weight
+	v.sum += weight * label
+	v.squares += weight * label * label
+}
+
+func (v *variance[T]) Remove(label, weight T) {
+	v.Add(label, -weight)
+}
+
+func (v *variance[T]) Impurity() T {
+	if v.total <= model.Epsilon {
+		return 0
+	}
+	var mean = v.sum / v.total
+	var r = v.squares/v.total - mean*mean
+	if r < 0 {
+		return 0
+	}
+	return r
+}
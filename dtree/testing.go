@@ -1,35 +1,151 @@
 package dtree
 
 import (
-	"math/rand"
 	"testing"
-	"time"
 
 	"github.com/gopherd/doge/constraints"
 	"github.com/gopherd/doge/container/slices"
+	"github.com/gopherd/doge/math/tensor"
 	"github.com/gopherd/ml/dataloader"
+	"github.com/gopherd/ml/evaluation"
+	"github.com/gopherd/ml/model"
 )
 
 // TestModel tests the model with train data from file
 func TestModel[T constraints.Float](filename string, m *Model[T], t *testing.T) {
-	rand.Seed(time.Now().UnixNano())
 	samples, err := dataloader.LoadCSVFile[T](filename)
 	if err != nil {
 		t.Fatalf("load test data error: %v", err)
 	}
-	slices.Shuffle(samples)
-	var split = len(samples) * 4 / 5
-	var trainData = samples[:split]
-	var testData = samples[split:]
-	m.Train(trainData)
-	var accurracy T
-	for _, x := range testData {
-		label := m.Predict(x.Attributes)
-		if x.Label == label {
-			accurracy++
+	trainData, testData := evaluation.TrainTestSplit(samples, 0.2)
+	m.Train(trainData, nil)
+	logAccuracy(m, testData, t)
+}
+
+// noisySamples returns n samples for each pair of values of two attributes,
+// attr[0] in {0, 1} and attr[1] in {0, 1, 2, 3}, labeled by attr[0]. Labels
+// of the first flipped samples of pairs (0, 1) and (1, 2) are flipped, so
+// that a tree fitting them splits attr[1] under both branches of attr[0].
+func noisySamples[T constraints.Float](n, flipped int) []model.Sample[T] {
+	var samples []model.Sample[T]
+	for a := 0; a < 2; a++ {
+		for b := 0; b < 4; b++ {
+			for i := 0; i < n; i++ {
+				var label = T(a)
+				if i < flipped && ((a == 0 && b == 1) || (a == 1 && b == 2)) {
+					label = 1 - label
+				}
+				samples = append(samples, model.Sample[T]{
+					Attributes: tensor.Vector[T]{T(a), T(b)},
+					Label:      label,
+				})
+			}
+		}
+	}
+	return samples
+}
+
+// checkPruning checks that the pruned tree is strictly smaller than the
+// unpruned tree, keeps the real split on attr[0] at root and is not less
+// accurate on validation samples.
+func checkPruning[T constraints.Float](unpruned, pruned *Model[T], validation []model.Sample[T], t *testing.T) {
+	t.Logf("nodes: %d => %d", unpruned.NumNode(), pruned.NumNode())
+	if pruned.NumNode() >= unpruned.NumNode() {
+		t.Fatalf("pruned tree has %d nodes, want less than %d nodes of unpruned tree:\n%s", pruned.NumNode(), unpruned.NumNode(), pruned.Stringify(nil))
+	}
+	var root = pruned.Root()
+	if root.NumChild() == 0 || root.GetChildByIndex(0).AttributeType != 0 {
+		t.Fatalf("pruned tree lost split on attr[0]:\n%s", pruned.Stringify(nil))
+	}
+	var before, after = evaluation.Accuracy[T](unpruned, validation), evaluation.Accuracy[T](pruned, validation)
+	if after < before {
+		t.Fatalf("validation accuracy decreased by pruning: %v => %v", before, after)
+	}
+}
+
+// TestPruning tests pruning on samples whose label is attr[0] with noisy
+// labels on some values of attr[1]: the pruned tree must be smaller than the
+// unpruned tree, keep the split on attr[0] and be not less accurate on a
+// clean validation set.
+func TestPruning[T constraints.Float](policy PolicyFunc[T], pruningType PruningType, t *testing.T, options ...Option[T]) {
+	var samples = noisySamples[T](3, 2)
+	var validation = noisySamples[T](1, 0)
+
+	var unpruned = NewModel(policy, NoPruning, options...)
+	unpruned.Train(samples, nil)
+	var pruned = NewModel(policy, pruningType, append(options, WithValidation(validation))...)
+	pruned.Train(samples, nil)
+	checkPruning(unpruned, pruned, validation, t)
+}
+
+// TestHoldout tests pruning by validation set held out from training samples
+// by WithValidationRatio: the pruned tree must be reproducible and pruned as
+// TestPruning, and no pruning happens if nothing is held out.
+func TestHoldout[T constraints.Float](policy PolicyFunc[T], pruningType PruningType, t *testing.T, options ...Option[T]) {
+	var samples = noisySamples[T](10, 2)
+	var validation = noisySamples[T](1, 0)
+
+	var unpruned = NewModel(policy, NoPruning, options...)
+	unpruned.Train(samples, nil)
+	var pruned = NewModel(policy, pruningType, append(options, WithValidationRatio[T](0.3))...)
+	pruned.Train(samples, nil)
+	checkPruning(unpruned, pruned, validation, t)
+
+	var again = NewModel(policy, pruningType, append(options, WithValidationRatio[T](0.3))...)
+	again.Train(samples, nil)
+	if again.Stringify(nil) != pruned.Stringify(nil) {
+		t.Fatalf("tree trained again:\n%s\nwant:\n%s", again.Stringify(nil), pruned.Stringify(nil))
+	}
+
+	var none = NewModel(policy, pruningType, append(options, WithValidationRatio[T](0))...)
+	none.Train(samples, nil)
+	if none.Stringify(nil) != unpruned.Stringify(nil) {
+		t.Fatalf("tree without validation set:\n%s\nwant:\n%s", none.Stringify(nil), unpruned.Stringify(nil))
+	}
+}
+
+// TestWeight tests that a sample with weight k trains the same tree as the
+// sample repeated k times.
+func TestWeight[T constraints.Float](filename string, policy PolicyFunc[T], t *testing.T, options ...Option[T]) {
+	samples, err := dataloader.LoadCSVFile[T](filename)
+	if err != nil {
+		t.Fatalf("load test data error: %v", err)
+	}
+	var repeated = slices.Clone(samples)
+	var weighted = slices.Clone(samples)
+	for i := 0; i < len(samples); i += 3 {
+		repeated = append(repeated, samples[i], samples[i])
+		weighted[i].Weight = 3
+	}
+	var m1 = NewModel(policy, NoPruning, options...)
+	m1.Train(repeated, nil)
+	var m2 = NewModel(policy, NoPruning, options...)
+	m2.Train(weighted, nil)
+	var s1, s2 = m1.Stringify(nil), m2.Stringify(nil)
+	if s1 != s2 {
+		t.Fatalf("weighted tree:\n%s\nwant:\n%s", s2, s1)
+	}
+}
+
+// TestEncoding tests that the model decoded from JSON and binary encoding
+// predicts the same labels as the trained model.
+func TestEncoding[T constraints.Float](filename string, policy PolicyFunc[T], t *testing.T, options ...Option[T]) {
+	samples, err := dataloader.LoadCSVFile[T](filename)
+	if err != nil {
+		t.Fatalf("load test data error: %v", err)
+	}
+	var m = NewModel(policy, NoPruning, options...)
+	m.Train(samples, nil)
+
+	var fromJSON, fromBinary = model.TestEncoding(m, func() *Model[T] { return NewModel[T](nil, NoPruning) }, samples, t)
+	for _, decoded := range []*Model[T]{fromJSON, fromBinary} {
+		if decoded.Stringify(nil) != m.Stringify(nil) {
+			t.Fatalf("decoded tree:\n%s\nwant:\n%s", decoded.Stringify(nil), m.Stringify(nil))
 		}
 	}
-	accurracy /= T(len(testData))
-	accurracy *= 100
+}
+
+func logAccuracy[T constraints.Float](m *Model[T], testData []model.Sample[T], t *testing.T) {
+	var accurracy = evaluation.Accuracy[T](m, testData) * 100
 	t.Logf("accurray: %d.%d%%", int(accurracy), int(accurracy*10)%10)
 }
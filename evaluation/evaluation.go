@@ -0,0 +1,308 @@
+// package evaluation implements splitting of samples and cross-validation of models.
+//
+// All splitters are driven by a fixed seed, so results are reproducible.
+// @see https://en.wikipedia.org/wiki/Cross-validation_(statistics)
+//
+package evaluation
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/metrics"
+	"github.com/gopherd/ml/model"
+)
+
+// DefaultSeed is the default seed of splitters
+const DefaultSeed = 1
+
+type options struct {
+	seed        int64
+	repeats     int
+	concurrency int
+}
+
+func defaultOptions() options {
+	return options{
+		seed:        DefaultSeed,
+		repeats:     1,
+		concurrency: runtime.NumCPU(),
+	}
+}
+
+// Option represents an option of splitters and CrossValidate
+type Option func(opt *options)
+
+func (opt *options) apply(options []Option) {
+	for _, o := range options {
+		o(opt)
+	}
+	if opt.repeats < 1 {
+		opt.repeats = 1
+	}
+	if opt.concurrency < 1 {
+		opt.concurrency = 1
+	}
+}
+
+// WithSeed sets seed for shuffling samples, default is DefaultSeed
+func WithSeed(seed int64) Option {
+	return func(opt *options) {
+		opt.seed = seed
+	}
+}
+
+// WithRepeats sets number of repetitions of k-fold splitting, each repetition
+// shuffles samples differently, default is 1
+func WithRepeats(n int) Option {
+	return func(opt *options) {
+		opt.repeats = n
+	}
+}
+
+// WithConcurrency sets maximum number of folds evaluated concurrently by
+// CrossValidate, default is number of CPUs
+func WithConcurrency(n int) Option {
+	return func(opt *options) {
+		opt.concurrency = n
+	}
+}
+
+// Fold represents a split of samples by indices. Train is nil if it's the
+// complement of Test, folds created by splitters of this package store Test
+// only, so that memory of n folds is O(n) instead of O(n²).
+type Fold struct {
+	Train []int
+	Test  []int
+}
+
+// TrainIndices returns indices of train samples of the fold, n is number of
+// samples
+func (f Fold) TrainIndices(n int) []int {
+	if f.Train != nil {
+		return f.Train
+	}
+	var tested = make([]bool, n)
+	for _, i := range f.Test {
+		tested[i] = true
+	}
+	var train = make([]int, 0, n-len(f.Test))
+	for i := range tested {
+		if !tested[i] {
+			train = append(train, i)
+		}
+	}
+	return train
+}
+
+// Samples returns train and test samples of the fold
+func Samples[T constraints.Float](samples []model.Sample[T], fold Fold) (train, test []model.Sample[T]) {
+	var indices = fold.TrainIndices(len(samples))
+	train = make([]model.Sample[T], len(indices))
+	for i, j := range indices {
+		train[i] = samples[j]
+	}
+	test = make([]model.Sample[T], len(fold.Test))
+	for i, j := range fold.Test {
+		test[i] = samples[j]
+	}
+	return
+}
+
+// Splitter splits samples into folds
+type Splitter[T constraints.Float] func(samples []model.Sample[T]) []Fold
+
+// TrainTestSplit shuffles samples and holds out testRatio of them as test set
+func TrainTestSplit[T constraints.Float](samples []model.Sample[T], testRatio T, options ...Option) (train, test []model.Sample[T]) {
+	var opt = defaultOptions()
+	opt.apply(options)
+	var indices = rand.New(rand.NewSource(opt.seed)).Perm(len(samples))
+	var n = int(testRatio * T(len(samples)))
+	return Samples(samples, Fold{Train: indices[n:], Test: indices[:n]})
+}
+
+// checkK panics if k is invalid for n samples
+func checkK(k, n int) {
+	if k < 2 {
+		panic("evaluation: number of folds must be at least 2")
+	}
+	if n >= 0 && k > n {
+		panic("evaluation: number of folds greater than number of samples")
+	}
+}
+
+// KFold splits shuffled samples into k folds of nearly equal size, each fold
+// is used once as test set. It panics if k < 2, and the splitter panics if k
+// is greater than number of samples.
+func KFold[T constraints.Float](k int, options ...Option) Splitter[T] {
+	checkK(k, -1)
+	var opt = defaultOptions()
+	opt.apply(options)
+	return func(samples []model.Sample[T]) []Fold {
+		checkK(k, len(samples))
+		var r = rand.New(rand.NewSource(opt.seed))
+		var folds []Fold
+		for i := 0; i < opt.repeats; i++ {
+			var assignments = make([]int, len(samples))
+			for j, index := range r.Perm(len(samples)) {
+				assignments[index] = j % k
+			}
+			folds = append(folds, makeFolds(assignments, k)...)
+		}
+		return folds
+	}
+}
+
+// StratifiedKFold splits samples into k folds like KFold, but each fold
+// preserves proportion of samples for each label.
+func StratifiedKFold[T constraints.Float](k int, options ...Option) Splitter[T] {
+	checkK(k, -1)
+	var opt = defaultOptions()
+	opt.apply(options)
+	return func(samples []model.Sample[T]) []Fold {
+		checkK(k, len(samples))
+		var groups = make(map[T][]int)
+		for i := range samples {
+			groups[samples[i].Label] = append(groups[samples[i].Label], i)
+		}
+		var labels = make([]T, 0, len(groups))
+		for label := range groups {
+			labels = append(labels, label)
+		}
+		sort.Slice(labels, func(i, j int) bool { return labels[i] < labels[j] })
+
+		var r = rand.New(rand.NewSource(opt.seed))
+		var folds []Fold
+		for i := 0; i < opt.repeats; i++ {
+			var assignments = make([]int, len(samples))
+			var next int
+			for _, label := range labels {
+				var group = groups[label]
+				for _, j := range r.Perm(len(group)) {
+					assignments[group[j]] = next % k
+					next++
+				}
+			}
+			folds = append(folds, makeFolds(assignments, k)...)
+		}
+		return folds
+	}
+}
+
+// LeaveOneOut splits n samples into n folds, each fold tests one sample
+func LeaveOneOut[T constraints.Float]() Splitter[T] {
+	return func(samples []model.Sample[T]) []Fold {
+		return makeFolds(tensor.RangeN(len(samples)), len(samples))
+	}
+}
+
+// makeFolds creates k folds, i-th sample is tested by fold assignments[i]
+func makeFolds(assignments []int, k int) []Fold {
+	var folds = make([]Fold, k)
+	for i, f := range assignments {
+		folds[f].Test = append(folds[f].Test, i)
+	}
+	return folds
+}
+
+// Metric scores a trained model on test samples
+type Metric[T constraints.Float] func(m model.Model[T], samples []model.Sample[T]) T
+
+// Predict returns predictions of m for samples
+func Predict[T constraints.Float](m model.Model[T], samples []model.Sample[T]) []T {
+	var predictions = make([]T, len(samples))
+	for i := range samples {
+		predictions[i] = m.Predict(samples[i].Attributes)
+	}
+	return predictions
+}
+
+// Predictions adapts a metric computed from predictions, e.g. functions of
+// package metrics, to Metric.
+func Predictions[T constraints.Float](f func(samples []model.Sample[T], predictions []T) T) Metric[T] {
+	return func(m model.Model[T], samples []model.Sample[T]) T {
+		return f(samples, Predict(m, samples))
+	}
+}
+
+// Accuracy returns weighted ratio of samples predicted correctly
+func Accuracy[T constraints.Float](m model.Model[T], samples []model.Sample[T]) T {
+	return metrics.Accuracy(samples, Predict(m, samples))
+}
+
+// MeanSquaredError returns weighted mean of squared errors
+func MeanSquaredError[T constraints.Float](m model.Model[T], samples []model.Sample[T]) T {
+	return metrics.MeanSquaredError(samples, Predict(m, samples))
+}
+
+// Result holds scores of cross-validation
+type Result[T constraints.Float] struct {
+	Folds []map[string]T // scores of each fold by metric name
+	Mean  map[string]T   // mean score over folds
+	Std   map[string]T   // standard deviation of scores over folds
+}
+
+// CrossValidate trains a model created by newModel on train set of each fold
+// and scores it on test set by metrics.
+func CrossValidate[T constraints.Float](
+	newModel func() model.Model[T],
+	samples []model.Sample[T],
+	splitter Splitter[T],
+	metrics map[string]Metric[T],
+	options ...Option,
+) *Result[T] {
+	var opt = defaultOptions()
+	opt.apply(options)
+	var folds = splitter(samples)
+	var result = &Result[T]{
+		Folds: make([]map[string]T, len(folds)),
+		Mean:  make(map[string]T),
+		Std:   make(map[string]T),
+	}
+	var wg sync.WaitGroup
+	var sem = make(chan struct{}, opt.concurrency)
+	for i := range folds {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			train, test := Samples(samples, folds[i])
+			var m = newModel()
+			m.Train(train, nil)
+			var scores = make(map[string]T, len(metrics))
+			for name, metric := range metrics {
+				scores[name] = metric(m, test)
+			}
+			result.Folds[i] = scores
+		}(i)
+	}
+	wg.Wait()
+
+	if len(folds) == 0 {
+		return result
+	}
+	var n = T(len(folds))
+	for name := range metrics {
+		var sum T
+		for _, scores := range result.Folds {
+			sum += scores[name]
+		}
+		var mean = sum / n
+		var variance T
+		for _, scores := range result.Folds {
+			var d = scores[name] - mean
+			variance += d * d
+		}
+		result.Mean[name] = mean
+		result.Std[name] = T(math.Sqrt(float64(variance / n)))
+	}
+	return result
+}
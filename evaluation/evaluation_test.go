@@ -0,0 +1,174 @@
+package evaluation_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/doge/operator"
+	"github.com/gopherd/ml/dtree"
+	"github.com/gopherd/ml/dtree/cart"
+	"github.com/gopherd/ml/evaluation"
+	"github.com/gopherd/ml/metrics"
+	"github.com/gopherd/ml/model"
+)
+
+func newSamples(n int) []model.Sample[float64] {
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]model.Sample[float64], n)
+	for i := range samples {
+		x, y := r.Float64(), r.Float64()
+		samples[i].Attributes = tensor.Vec(x, y)
+		// 1:3 imbalanced labels
+		samples[i].Label = operator.If(x+y > 1.4, 1.0, 0.0)
+	}
+	return samples
+}
+
+func checkFolds(t *testing.T, folds []evaluation.Fold, n, repeats int) {
+	var tested = make([]int, n)
+	for i, fold := range folds {
+		var train = fold.TrainIndices(n)
+		if len(train)+len(fold.Test) != n {
+			t.Fatalf("fold %d: %d train + %d test samples, want %d", i, len(train), len(fold.Test), n)
+		}
+		var seen = make([]bool, n)
+		for _, j := range append(train, fold.Test...) {
+			if seen[j] {
+				t.Fatalf("fold %d: sample %d both trained and tested", i, j)
+			}
+			seen[j] = true
+		}
+		for _, j := range fold.Test {
+			tested[j]++
+		}
+	}
+	for i, count := range tested {
+		if count != repeats {
+			t.Fatalf("sample %d tested %d times, want %d", i, count, repeats)
+		}
+	}
+}
+
+func TestKFold(t *testing.T) {
+	var samples = newSamples(103)
+	var folds = evaluation.KFold[float64](5, evaluation.WithRepeats(3))(samples)
+	if len(folds) != 15 {
+		t.Fatalf("got %d folds, want 15", len(folds))
+	}
+	checkFolds(t, folds, len(samples), 3)
+	for _, fold := range folds {
+		if n := len(fold.Test); n < 20 || n > 21 {
+			t.Fatalf("unbalanced fold: %d test samples", n)
+		}
+	}
+	if reflect.DeepEqual(folds[0], folds[5]) {
+		t.Fatalf("repetitions should shuffle differently")
+	}
+	if again := evaluation.KFold[float64](5, evaluation.WithRepeats(3))(samples); !reflect.DeepEqual(folds, again) {
+		t.Fatalf("same seed should split samples identically")
+	}
+	if other := evaluation.KFold[float64](5, evaluation.WithSeed(2))(samples); reflect.DeepEqual(folds[:5], other) {
+		t.Fatalf("different seeds should split samples differently")
+	}
+}
+
+func TestStratifiedKFold(t *testing.T) {
+	var samples = newSamples(200)
+	var positives int
+	for _, x := range samples {
+		if x.Label == 1 {
+			positives++
+		}
+	}
+	var folds = evaluation.StratifiedKFold[float64](4)(samples)
+	checkFolds(t, folds, len(samples), 1)
+	for i, fold := range folds {
+		var n int
+		for _, j := range fold.Test {
+			if samples[j].Label == 1 {
+				n++
+			}
+		}
+		if n < positives/4 || n > positives/4+1 {
+			t.Fatalf("fold %d: %d positive samples, want about %d", i, n, positives/4)
+		}
+	}
+}
+
+func TestLeaveOneOut(t *testing.T) {
+	var samples = newSamples(10)
+	var folds = evaluation.LeaveOneOut[float64]()(samples)
+	if len(folds) != len(samples) {
+		t.Fatalf("got %d folds, want %d", len(folds), len(samples))
+	}
+	checkFolds(t, folds, len(samples), 1)
+	for i, fold := range folds {
+		if fold.Train != nil {
+			t.Fatalf("fold %d: train indices should be derived from test indices", i)
+		}
+	}
+}
+
+func TestInvalidK(t *testing.T) {
+	var samples = newSamples(10)
+	for _, tc := range []struct {
+		name string
+		f    func()
+	}{
+		{"k=0", func() { evaluation.KFold[float64](0) }},
+		{"k=1", func() { evaluation.StratifiedKFold[float64](1) }},
+		{"k>n", func() { evaluation.KFold[float64](11)(samples) }},
+		{"stratified k>n", func() { evaluation.StratifiedKFold[float64](11)(samples) }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: should panic", tc.name)
+				}
+			}()
+			tc.f()
+		}()
+	}
+}
+
+func TestTrainTestSplit(t *testing.T) {
+	var samples = newSamples(100)
+	train, test := evaluation.TrainTestSplit(samples, 0.2)
+	if len(train) != 80 || len(test) != 20 {
+		t.Fatalf("got %d train and %d test samples", len(train), len(test))
+	}
+	train2, _ := evaluation.TrainTestSplit(samples, 0.2)
+	if !reflect.DeepEqual(train, train2) {
+		t.Fatalf("same seed should split samples identically")
+	}
+}
+
+func TestCrossValidate(t *testing.T) {
+	type T = float64
+	var samples = newSamples(300)
+	var result = evaluation.CrossValidate(func() model.Model[T] {
+		return cart.NewModel(dtree.PrePruning,
+			dtree.WithMaxDepth[T](4),
+			dtree.WithContinuous[T](0, 1),
+		)
+	}, samples, evaluation.StratifiedKFold[T](5, evaluation.WithRepeats(2)), map[string]evaluation.Metric[T]{
+		"accuracy": evaluation.Accuracy[T],
+		"mse":      evaluation.MeanSquaredError[T],
+		"macro-f1": evaluation.Predictions(func(samples []model.Sample[T], predictions []T) T {
+			return metrics.NewConfusionMatrix(samples, predictions).MacroF1()
+		}),
+	})
+	if len(result.Folds) != 10 {
+		t.Fatalf("got %d folds, want 10", len(result.Folds))
+	}
+	t.Logf("accuracy: %v ± %v, macro-f1: %v", result.Mean["accuracy"], result.Std["accuracy"], result.Mean["macro-f1"])
+	if result.Mean["accuracy"] < 0.85 {
+		t.Fatalf("accuracy too low: %v", result.Mean["accuracy"])
+	}
+	// labels are 0/1, so mse equals error rate
+	if d := result.Mean["mse"] - (1 - result.Mean["accuracy"]); d > 1e-9 || d < -1e-9 {
+		t.Fatalf("mse %v mismatch with accuracy %v", result.Mean["mse"], result.Mean["accuracy"])
+	}
+}
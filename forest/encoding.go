@@ -0,0 +1,56 @@
+package forest
+
+import (
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/dtree"
+	"github.com/gopherd/ml/model"
+)
+
+// forestState is the encoded form of Forest, trees are encoded by themselves
+type forestState[T constraints.Float] struct {
+	OOBError T                 `json:"oob_error"`
+	Trees    []*dtree.Model[T] `json:"trees"`
+}
+
+func (f *Forest[T]) state() forestState[T] {
+	return forestState[T]{
+		OOBError: f.oobError,
+		Trees:    f.trees,
+	}
+}
+
+func (f *Forest[T]) setState(s forestState[T]) {
+	f.oobError = s.OOBError
+	f.trees = s.Trees
+}
+
+// MarshalJSON implements json.Marshaler
+func (f *Forest[T]) MarshalJSON() ([]byte, error) {
+	return model.EncodeJSON(f.state())
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoded trees can predict but
+// can not be trained again.
+func (f *Forest[T]) UnmarshalJSON(data []byte) error {
+	var s forestState[T]
+	if err := model.DecodeJSON(data, &s); err != nil {
+		return err
+	}
+	f.setState(s)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (f *Forest[T]) MarshalBinary() ([]byte, error) {
+	return model.EncodeBinary(f.state())
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (f *Forest[T]) UnmarshalBinary(data []byte) error {
+	var s forestState[T]
+	if err := model.DecodeBinary(data, &s); err != nil {
+		return err
+	}
+	f.setState(s)
+	return nil
+}
@@ -0,0 +1,207 @@
+// package forest implements random forest built on decision trees.
+//
+// @see https://en.wikipedia.org/wiki/Random_forest
+//
+package forest
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/dtree"
+	"github.com/gopherd/ml/model"
+)
+
+// DefaultSeed is the default seed of bootstrap sampling
+const DefaultSeed = 1
+
+type options struct {
+	seed        int64
+	concurrency int
+}
+
+func defaultOptions() options {
+	return options{
+		seed:        DefaultSeed,
+		concurrency: runtime.NumCPU(),
+	}
+}
+
+// Option represents an option of forest
+type Option func(opt *options)
+
+func (opt *options) apply(options []Option) {
+	for _, o := range options {
+		o(opt)
+	}
+}
+
+// WithSeed sets seed for bootstrap sampling and random sources of trees,
+// default is DefaultSeed
+func WithSeed(seed int64) Option {
+	return func(opt *options) {
+		opt.seed = seed
+	}
+}
+
+// WithConcurrency sets maximum number of trees trained concurrently,
+// default is number of CPUs
+func WithConcurrency(n int) Option {
+	return func(opt *options) {
+		opt.concurrency = n
+	}
+}
+
+var _ model.Model[float64] = (*Forest[float64])(nil)
+
+// Forest is an ensemble of decision trees trained on bootstrap samples,
+// it predicts by majority vote for classification or by mean for regression.
+type Forest[T constraints.Float] struct {
+	numTree  int
+	newTree  func() *dtree.Model[T]
+	options  options
+	trees    []*dtree.Model[T]
+	oobError T
+}
+
+// New creates a forest which has numTree trees created by newTree, trees
+// usually select attributes from random subsets, e.g.
+//
+//	forest.New(100, func() *dtree.Model[T] {
+//		return dtree.NewModel(cart.Policy[T], dtree.NoPruning, dtree.WithRandomSubspace[T](true))
+//	})
+//
+// Each tree is seeded by the forest, so that trees are reproducible.
+func New[T constraints.Float](numTree int, newTree func() *dtree.Model[T], options ...Option) *Forest[T] {
+	var f = &Forest[T]{
+		numTree: numTree,
+		newTree: newTree,
+		options: defaultOptions(),
+	}
+	f.options.apply(options)
+	if f.options.concurrency < 1 {
+		f.options.concurrency = 1
+	}
+	return f
+}
+
+// Trees returns trained trees of the forest
+func (f *Forest[T]) Trees() []*dtree.Model[T] {
+	return f.trees
+}
+
+// OOBError returns out-of-bag error of the forest: error rate for
+// classification or mean squared error for regression. Each sample is
+// predicted by trees whose bootstrap samples don't contain it.
+func (f *Forest[T]) OOBError() T {
+	return f.oobError
+}
+
+// Train trains trees on bootstrap samples concurrently, each tree draws its
+// bootstrap samples from its own random source seeded by the forest.
+func (f *Forest[T]) Train(samples []model.Sample[T], tracker model.Tracker) {
+	f.trees = make([]*dtree.Model[T], f.numTree)
+	f.oobError = 0
+	var inBags = make([][]bool, f.numTree)
+	var sem = make(chan struct{}, f.options.concurrency)
+	var wg sync.WaitGroup
+	var seeds = rand.New(rand.NewSource(f.options.seed))
+	for i := range f.trees {
+		var i = i
+		var tree = f.newTree()
+		var r = rand.New(rand.NewSource(seeds.Int63()))
+		tree.SetSeed(r.Int63())
+		f.trees[i] = tree
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			var inBag = make([]bool, len(samples))
+			var bootstrap = make([]model.Sample[T], len(samples))
+			for j := range bootstrap {
+				k := r.Intn(len(samples))
+				bootstrap[j] = samples[k]
+				inBag[k] = true
+			}
+			inBags[i] = inBag
+			tree.Train(bootstrap, nil)
+		}()
+	}
+	wg.Wait()
+	f.oobError = f.computeOOBError(samples, inBags)
+}
+
+func (f *Forest[T]) computeOOBError(samples []model.Sample[T], inBags [][]bool) T {
+	var sum, total T
+	var predictions = make([]T, 0, len(f.trees))
+	for j, x := range samples {
+		predictions = predictions[:0]
+		for i, tree := range f.trees {
+			if !inBags[i][j] {
+				predictions = append(predictions, tree.Predict(x.Attributes))
+			}
+		}
+		if len(predictions) == 0 {
+			continue
+		}
+		var w = model.WeightOf(x)
+		var y = f.aggregate(predictions)
+		if f.regression() {
+			sum += w * (y - x.Label) * (y - x.Label)
+		} else if y != x.Label {
+			sum += w
+		}
+		total += w
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+func (f *Forest[T]) regression() bool {
+	return len(f.trees) > 0 && f.trees[0].Regression()
+}
+
+// aggregate combines predictions of trees: mean for regression, or the most
+// voted label for classification (the smaller label wins if tied).
+func (f *Forest[T]) aggregate(predictions []T) T {
+	if f.regression() {
+		var sum T
+		for _, y := range predictions {
+			sum += y
+		}
+		return sum / T(len(predictions))
+	}
+	var votes = make(map[T]int)
+	for _, y := range predictions {
+		votes[y]++
+	}
+	var best T
+	var bestVotes int
+	for y, n := range votes {
+		if n > bestVotes || (n == bestVotes && y < best) {
+			best = y
+			bestVotes = n
+		}
+	}
+	return best
+}
+
+// Predict predicts label for x by all trees
+func (f *Forest[T]) Predict(x tensor.Vector[T]) T {
+	if len(f.trees) == 0 {
+		return 0
+	}
+	var predictions = make([]T, len(f.trees))
+	for i, tree := range f.trees {
+		predictions[i] = tree.Predict(x)
+	}
+	return f.aggregate(predictions)
+}
@@ -0,0 +1,130 @@
+package forest_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/doge/operator"
+	"github.com/gopherd/ml/dtree"
+	"github.com/gopherd/ml/dtree/cart"
+	"github.com/gopherd/ml/forest"
+	"github.com/gopherd/ml/model"
+)
+
+func TestClassification(t *testing.T) {
+	type T = float64
+	var samples = make([]model.Sample[T], 400)
+	for i := range samples {
+		x, y, z := rand.Float64(), rand.Float64(), rand.Float64()
+		samples[i].Attributes = tensor.Vec(x, y, z)
+		samples[i].Label = operator.If(x+y > 1, 1.0, 0.0)
+	}
+	var trainData, testData = samples[:300], samples[300:]
+	var f = forest.New(32, func() *dtree.Model[T] {
+		return dtree.NewModel(cart.Policy[T], dtree.NoPruning,
+			dtree.WithRandomSubspace[T](true),
+			dtree.WithBinary[T](true),
+			dtree.WithContinuous[T](0, 1, 2),
+			dtree.WithImpurity(model.WeightedGiniSet[[]model.Sample[T]]),
+		)
+	})
+	f.Train(trainData, nil)
+	if n := len(f.Trees()); n != 32 {
+		t.Fatalf("number of trees: want 32, got %d", n)
+	}
+	var errors int
+	for _, x := range testData {
+		if f.Predict(x.Attributes) != x.Label {
+			errors++
+		}
+	}
+	var testError = T(errors) / T(len(testData))
+	t.Logf("oob error: %v, test error: %v", f.OOBError(), testError)
+	if testError > 0.15 {
+		t.Fatalf("test error too large: %v", testError)
+	}
+	if f.OOBError() > 0.15 {
+		t.Fatalf("oob error too large: %v", f.OOBError())
+	}
+}
+
+func TestRegression(t *testing.T) {
+	type T = float64
+	var samples = make([]model.Sample[T], 300)
+	for i := range samples {
+		x := rand.Float64()
+		samples[i].Attributes = tensor.Vec(x, rand.Float64())
+		samples[i].Label = math.Sin(2 * math.Pi * x)
+	}
+	var f = forest.New(16, func() *dtree.Model[T] {
+		return cart.NewRegressionModel(dtree.PrePruning,
+			dtree.WithContinuous[T](0, 1),
+			dtree.WithMinSamplesLeaf[T](3),
+		)
+	}, forest.WithConcurrency(2))
+	f.Train(samples, nil)
+	t.Logf("oob error: %v", f.OOBError())
+	if f.OOBError() > 0.05 {
+		t.Fatalf("oob error too large: %v", f.OOBError())
+	}
+}
+
+func TestSeed(t *testing.T) {
+	type T = float64
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]model.Sample[T], 200)
+	for i := range samples {
+		x, y, z := r.Float64(), r.Float64(), r.Float64()
+		samples[i].Attributes = tensor.Vec(x, y, z)
+		samples[i].Label = operator.If(x+y > 1, 1.0, 0.0)
+	}
+	var train = func(seed int64) *forest.Forest[T] {
+		var f = forest.New(8, func() *dtree.Model[T] {
+			return dtree.NewModel(cart.Policy[T], dtree.NoPruning,
+				dtree.WithRandomSubspace[T](true),
+				dtree.WithContinuous[T](0, 1, 2),
+			)
+		}, forest.WithSeed(seed))
+		f.Train(samples, nil)
+		return f
+	}
+	var stringify = func(f *forest.Forest[T]) string {
+		var s string
+		for _, tree := range f.Trees() {
+			s += tree.Stringify(nil)
+		}
+		return s
+	}
+	var f1, f2, f3 = train(1), train(1), train(2)
+	if stringify(f1) != stringify(f2) || f1.OOBError() != f2.OOBError() {
+		t.Fatalf("forests trained by same seed differ, oob errors: %v and %v", f1.OOBError(), f2.OOBError())
+	}
+	if stringify(f1) == stringify(f3) {
+		t.Fatalf("forests trained by different seeds are same")
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	type T = float64
+	var samples = make([]model.Sample[T], 200)
+	for i := range samples {
+		x, y := rand.Float64(), rand.Float64()
+		samples[i].Attributes = tensor.Vec(x, y)
+		samples[i].Label = operator.If(x > y, 1.0, 0.0)
+	}
+	var f = forest.New(8, func() *dtree.Model[T] {
+		return dtree.NewModel(cart.Policy[T], dtree.NoPruning,
+			dtree.WithRandomSubspace[T](true),
+			dtree.WithBinary[T](true),
+			dtree.WithContinuous[T](0, 1),
+		)
+	})
+	f.Train(samples, nil)
+
+	var f1, f2 = model.TestEncoding(f, func() *forest.Forest[T] { return forest.New[T](0, nil) }, samples, t)
+	if f1.OOBError() != f.OOBError() || len(f2.Trees()) != len(f.Trees()) {
+		t.Fatalf("decoded forest mismatch")
+	}
+}
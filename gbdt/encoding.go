@@ -0,0 +1,103 @@
+package gbdt
+
+import (
+	"errors"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/dtree"
+	"github.com/gopherd/ml/model"
+)
+
+// ErrLossMismatched is returned when decoding a model trained by a custom loss
+// into a model which is not created with a custom loss
+var ErrLossMismatched = errors.New("gbdt: loss mismatched")
+
+// names of built-in losses
+const (
+	squaredLoss  = "squared"
+	logisticLoss = "logistic"
+)
+
+// lossName returns name of built-in loss, or empty string for custom loss
+func lossName[T constraints.Float](loss Loss[T]) string {
+	switch loss.(type) {
+	case Squared[T], *Squared[T]:
+		return squaredLoss
+	case Logistic[T], *Logistic[T]:
+		return logisticLoss
+	default:
+		return ""
+	}
+}
+
+// modelState is the encoded form of Model. Built-in loss is encoded by name
+// and restored by decoding, custom loss is not encoded and the decoding model
+// should be created with the same loss.
+type modelState[T constraints.Float] struct {
+	Loss             string            `json:"loss,omitempty"`
+	Init             T                 `json:"init"`
+	LearningRate     T                 `json:"learning_rate"`
+	Trees            []*dtree.Model[T] `json:"trees"`
+	TrainLosses      []T               `json:"train_losses,omitempty"`
+	ValidationLosses []T               `json:"validation_losses,omitempty"`
+}
+
+func (m *Model[T]) state() modelState[T] {
+	return modelState[T]{
+		Loss:             lossName(m.loss),
+		Init:             m.init,
+		LearningRate:     m.options.learningRate,
+		Trees:            m.trees,
+		TrainLosses:      m.trainLosses,
+		ValidationLosses: m.validationLosses,
+	}
+}
+
+func (m *Model[T]) setState(s modelState[T]) error {
+	switch s.Loss {
+	case squaredLoss:
+		m.loss = Squared[T]{}
+	case logisticLoss:
+		m.loss = Logistic[T]{}
+	case "":
+		if m.loss == nil || lossName(m.loss) != "" {
+			return ErrLossMismatched
+		}
+	default:
+		return ErrLossMismatched
+	}
+	m.init = s.Init
+	m.options.learningRate = s.LearningRate
+	m.trees = s.Trees
+	m.trainLosses = s.TrainLosses
+	m.validationLosses = s.ValidationLosses
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *Model[T]) MarshalJSON() ([]byte, error) {
+	return model.EncodeJSON(m.state())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (m *Model[T]) UnmarshalJSON(data []byte) error {
+	var s modelState[T]
+	if err := model.DecodeJSON(data, &s); err != nil {
+		return err
+	}
+	return m.setState(s)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (m *Model[T]) MarshalBinary() ([]byte, error) {
+	return model.EncodeBinary(m.state())
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (m *Model[T]) UnmarshalBinary(data []byte) error {
+	var s modelState[T]
+	if err := model.DecodeBinary(data, &s); err != nil {
+		return err
+	}
+	return m.setState(s)
+}
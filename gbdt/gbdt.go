@@ -0,0 +1,236 @@
+// package gbdt implements gradient boosted decision trees for regression and
+// binary classification.
+//
+// @see https://en.wikipedia.org/wiki/Gradient_boosting
+//
+package gbdt
+
+import (
+	"math/rand"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/dtree"
+	"github.com/gopherd/ml/dtree/cart"
+	"github.com/gopherd/ml/model"
+)
+
+// DefaultSeed is the default seed of subsampling
+const DefaultSeed = 1
+
+type options[T constraints.Float] struct {
+	learningRate T
+	subsample    T
+	seed         int64
+	maxDepth     int
+	newTree      func(dim int) *dtree.Model[T]
+	validation   []model.Sample[T]
+	patience     int
+}
+
+func defaultOptions[T constraints.Float]() options[T] {
+	return options[T]{
+		learningRate: 0.1,
+		subsample:    1,
+		seed:         DefaultSeed,
+		maxDepth:     3,
+	}
+}
+
+// Option represents an option of gradient boosting model
+type Option[T constraints.Float] func(opt *options[T])
+
+func (opt *options[T]) apply(options []Option[T]) {
+	for _, o := range options {
+		o(opt)
+	}
+}
+
+// WithLearningRate sets shrinkage of each tree, default is 0.1
+func WithLearningRate[T constraints.Float](rate T) Option[T] {
+	return func(opt *options[T]) {
+		opt.learningRate = rate
+	}
+}
+
+// WithSubsample sets fraction of samples used to fit each tree, default is 1
+func WithSubsample[T constraints.Float](fraction T) Option[T] {
+	return func(opt *options[T]) {
+		opt.subsample = fraction
+	}
+}
+
+// WithSeed sets seed for subsampling, default is DefaultSeed
+func WithSeed[T constraints.Float](seed int64) Option[T] {
+	return func(opt *options[T]) {
+		opt.seed = seed
+	}
+}
+
+// WithMaxDepth sets maximum depth of default trees, default is 3
+func WithMaxDepth[T constraints.Float](depth int) Option[T] {
+	return func(opt *options[T]) {
+		opt.maxDepth = depth
+	}
+}
+
+// WithTree sets factory of regression trees fitted to pseudo-residuals, dim
+// is number of attributes. Default trees are binary CART regression trees
+// which treat all attributes as continuous.
+func WithTree[T constraints.Float](newTree func(dim int) *dtree.Model[T]) Option[T] {
+	return func(opt *options[T]) {
+		opt.newTree = newTree
+	}
+}
+
+// WithEarlyStopping sets validation set and stops training if loss on
+// validation set doesn't decrease for patience rounds. After training, the
+// model and its losses are truncated to the round which has minimum
+// validation loss.
+func WithEarlyStopping[T constraints.Float](validation []model.Sample[T], patience int) Option[T] {
+	return func(opt *options[T]) {
+		opt.validation = validation
+		opt.patience = patience
+	}
+}
+
+var _ model.Model[float64] = (*Model[float64])(nil)
+
+// Model is an additive model of regression trees:
+//
+//	F(x) = f₀ + η‧Σₘhₘ(x)
+//
+// where hₘ is fitted to pseudo-residuals of loss at Fₘ₋₁.
+type Model[T constraints.Float] struct {
+	loss    Loss[T]
+	rounds  int
+	options options[T]
+
+	init             T
+	trees            []*dtree.Model[T]
+	trainLosses      []T
+	validationLosses []T
+}
+
+// NewModel creates a gradient boosting model which fits at most rounds trees
+func NewModel[T constraints.Float](loss Loss[T], rounds int, options ...Option[T]) *Model[T] {
+	var m = &Model[T]{
+		loss:    loss,
+		rounds:  rounds,
+		options: defaultOptions[T](),
+	}
+	m.options.apply(options)
+	if m.options.newTree == nil {
+		var depth = m.options.maxDepth
+		m.options.newTree = func(dim int) *dtree.Model[T] {
+			return cart.NewRegressionModel(dtree.PrePruning,
+				dtree.WithMaxDepth[T](depth),
+				dtree.WithContinuous[T](tensor.RangeN(dim)...),
+			)
+		}
+	}
+	return m
+}
+
+// Trees returns fitted trees
+func (m *Model[T]) Trees() []*dtree.Model[T] {
+	return m.trees
+}
+
+// TrainLosses returns mean loss on training set after each round
+func (m *Model[T]) TrainLosses() []T {
+	return m.trainLosses
+}
+
+// ValidationLosses returns mean loss on validation set after each round
+func (m *Model[T]) ValidationLosses() []T {
+	return m.validationLosses
+}
+
+// Train fits trees round by round
+func (m *Model[T]) Train(samples []model.Sample[T], tracker model.Tracker) {
+	m.trees = m.trees[:0]
+	m.trainLosses = m.trainLosses[:0]
+	m.validationLosses = m.validationLosses[:0]
+	if len(samples) == 0 {
+		return
+	}
+	var dim = samples[0].Attributes.Dim()
+	var validation = m.options.validation
+	m.init = m.loss.Init(samples)
+	var f = tensor.Repeat(m.init, len(samples))
+	var fv = tensor.Repeat(m.init, len(validation))
+	var residuals = make([]model.Sample[T], len(samples))
+	copy(residuals, samples)
+	var indices = tensor.RangeN(len(samples))
+	var n = int(m.options.subsample * T(len(samples)))
+	if n < 1 || n > len(samples) {
+		n = len(samples)
+	}
+	var subset = make([]model.Sample[T], n)
+	var r = rand.New(rand.NewSource(m.options.seed))
+	var best = -1
+	for round := 0; round < m.rounds; round++ {
+		for i := range residuals {
+			residuals[i].Label = m.loss.Residual(samples[i].Label, f[i])
+		}
+		var train = residuals
+		if n < len(samples) {
+			r.Shuffle(len(indices), func(i, j int) {
+				indices[i], indices[j] = indices[j], indices[i]
+			})
+			for i := range subset {
+				subset[i] = residuals[indices[i]]
+			}
+			train = subset
+		}
+		var tree = m.options.newTree(dim)
+		tree.Train(train, nil)
+		m.trees = append(m.trees, tree)
+		for i := range samples {
+			f[i] += m.options.learningRate * tree.Predict(samples[i].Attributes)
+		}
+		m.trainLosses = append(m.trainLosses, m.meanLoss(samples, f))
+		if len(validation) == 0 {
+			continue
+		}
+		for i := range validation {
+			fv[i] += m.options.learningRate * tree.Predict(validation[i].Attributes)
+		}
+		m.validationLosses = append(m.validationLosses, m.meanLoss(validation, fv))
+		if best < 0 || m.validationLosses[round] < m.validationLosses[best] {
+			best = round
+		} else if m.options.patience > 0 && round-best >= m.options.patience {
+			break
+		}
+	}
+	if best >= 0 {
+		m.trees = m.trees[:best+1]
+		m.trainLosses = m.trainLosses[:best+1]
+		m.validationLosses = m.validationLosses[:best+1]
+	}
+}
+
+func (m *Model[T]) meanLoss(samples []model.Sample[T], f []T) T {
+	var sum, total T
+	for i := range samples {
+		var w = model.WeightOf(samples[i])
+		sum += w * m.loss.Loss(samples[i].Label, f[i])
+		total += w
+	}
+	return sum / total
+}
+
+// Decision returns raw prediction F(x), e.g. log-odds for logistic loss
+func (m *Model[T]) Decision(x tensor.Vector[T]) T {
+	var f = m.init
+	for _, tree := range m.trees {
+		f += m.options.learningRate * tree.Predict(x)
+	}
+	return f
+}
+
+// Predict predicts label for x
+func (m *Model[T]) Predict(x tensor.Vector[T]) T {
+	return m.loss.Output(m.Decision(x))
+}
@@ -0,0 +1,142 @@
+package gbdt_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/doge/operator"
+	"github.com/gopherd/ml/gbdt"
+	"github.com/gopherd/ml/model"
+)
+
+func TestRegression(t *testing.T) {
+	type T = float64
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]model.Sample[T], 300)
+	for i := range samples {
+		x, y := r.Float64(), r.Float64()
+		samples[i].Attributes = tensor.Vec(x, y)
+		samples[i].Label = math.Sin(2*math.Pi*x) + y*y
+	}
+	var m = gbdt.NewModel[T](gbdt.Squared[T]{}, 100, gbdt.WithSubsample[T](0.8))
+	m.Train(samples, nil)
+	var losses = m.TrainLosses()
+	t.Logf("loss: %v => %v", losses[0], losses[len(losses)-1])
+	if losses[len(losses)-1] > 0.01 {
+		t.Fatalf("loss too large: %v", losses[len(losses)-1])
+	}
+}
+
+func TestClassification(t *testing.T) {
+	type T = float64
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]model.Sample[T], 500)
+	for i := range samples {
+		x, y := r.Float64()*2-1, r.Float64()*2-1
+		samples[i].Attributes = tensor.Vec(x, y)
+		samples[i].Label = operator.If(x*x+y*y < 0.5, 1.0, 0.0)
+	}
+	var trainData, validation = samples[:400], samples[400:]
+	var m = gbdt.NewModel[T](gbdt.Logistic[T]{}, 500,
+		gbdt.WithLearningRate[T](0.3),
+		gbdt.WithEarlyStopping(validation, 10),
+	)
+	m.Train(trainData, nil)
+	var errors int
+	for _, x := range validation {
+		if m.Predict(x.Attributes) != x.Label {
+			errors++
+		}
+	}
+	var rate = T(errors) / T(len(validation))
+	var losses = m.ValidationLosses()
+	t.Logf("trees: %d, error rate: %v", len(m.Trees()), rate)
+	if len(m.Trees()) == 500 {
+		t.Fatalf("early stopping not triggered")
+	}
+	if len(losses) != len(m.Trees()) || len(m.TrainLosses()) != len(m.Trees()) {
+		t.Fatalf("losses not truncated with trees: %d trees, %d train losses, %d validation losses",
+			len(m.Trees()), len(m.TrainLosses()), len(losses))
+	}
+	for _, loss := range losses {
+		if loss < losses[len(losses)-1] {
+			t.Fatalf("model not truncated to minimum validation loss")
+		}
+	}
+	if rate > 0.1 {
+		t.Fatalf("error rate too large: %v", rate)
+	}
+}
+
+func TestLogisticLoss(t *testing.T) {
+	type T = float64
+	var loss gbdt.Logistic[T]
+	for _, tc := range []struct {
+		y, f, want T
+	}{
+		{1, 0, math.Ln2},
+		{0, 0, math.Ln2},
+		{1, 1000, 0},
+		{0, 1000, 1000},
+		{1, -1000, 1000},
+		{0, -1000, 0},
+	} {
+		if got := loss.Loss(tc.y, tc.f); math.IsInf(got, 0) || math.Abs(got-tc.want) > 1e-9 {
+			t.Fatalf("loss(%v, %v): got %v, want %v", tc.y, tc.f, got, tc.want)
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	type T = float64
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]model.Sample[T], 200)
+	for i := range samples {
+		x, y := r.Float64(), r.Float64()
+		samples[i].Attributes = tensor.Vec(x, y)
+		samples[i].Label = math.Sin(2*math.Pi*x) + y
+	}
+	var m = gbdt.NewModel[T](gbdt.Squared[T]{}, 20, gbdt.WithLearningRate[T](0.2))
+	m.Train(samples, nil)
+
+	model.TestEncoding(m, func() *gbdt.Model[T] { return gbdt.NewModel[T](gbdt.Squared[T]{}, 0) }, samples, t)
+}
+
+// custom is a custom loss which is not encoded
+type custom struct {
+	gbdt.Squared[float64]
+}
+
+func TestLossEncoding(t *testing.T) {
+	type T = float64
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]model.Sample[T], 200)
+	for i := range samples {
+		x, y := r.Float64()*2-1, r.Float64()*2-1
+		samples[i].Attributes = tensor.Vec(x, y)
+		samples[i].Label = operator.If(x*x+y*y < 0.5, 1.0, 0.0)
+	}
+	var m = gbdt.NewModel[T](gbdt.Logistic[T]{}, 20)
+	m.Train(samples, nil)
+	// loss is restored by decoding
+	model.TestEncoding(m, func() *gbdt.Model[T] { return new(gbdt.Model[T]) }, samples, t)
+	model.TestEncoding(m, func() *gbdt.Model[T] { return gbdt.NewModel[T](gbdt.Squared[T]{}, 0) }, samples, t)
+
+	// custom loss must be provided by decoding model
+	var c = gbdt.NewModel[T](custom{}, 5)
+	c.Train(samples, nil)
+	data, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal binary error: %v", err)
+	}
+	for _, decoded := range []*gbdt.Model[T]{new(gbdt.Model[T]), gbdt.NewModel[T](gbdt.Squared[T]{}, 0)} {
+		if err := decoded.UnmarshalBinary(data); err != gbdt.ErrLossMismatched {
+			t.Fatalf("unmarshal binary: got error %v, want %v", err, gbdt.ErrLossMismatched)
+		}
+	}
+	if err := gbdt.NewModel[T](custom{}, 0).UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal binary error: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package gbdt
+
+import (
+	"math"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/model"
+)
+
+// Loss represents a differentiable loss function of gradient boosting
+type Loss[T constraints.Float] interface {
+	// Init returns the constant prediction which minimizes loss of samples
+	Init(samples []model.Sample[T]) T
+	// Loss computes loss of raw prediction f for label y
+	Loss(y, f T) T
+	// Residual computes pseudo-residual, i.e. negative gradient of loss at f
+	Residual(y, f T) T
+	// Output converts raw prediction f to final prediction
+	Output(f T) T
+}
+
+// Squared is squared error loss for regression: L = (y-f)²/2
+type Squared[T constraints.Float] struct{}
+
+func (Squared[T]) Init(samples []model.Sample[T]) T {
+	return model.WeightedMean(samples)
+}
+
+func (Squared[T]) Loss(y, f T) T {
+	return (y - f) * (y - f) / 2
+}
+
+func (Squared[T]) Residual(y, f T) T {
+	return y - f
+}
+
+func (Squared[T]) Output(f T) T {
+	return f
+}
+
+// Logistic is logistic loss for binary classification, labels must be 0 or 1
+// and raw prediction f is log-odds of label 1:
+//
+//	L = -y‧log(p) - (1-y)‧log(1-p), p = sigmoid(f)
+type Logistic[T constraints.Float] struct{}
+
+func (Logistic[T]) Init(samples []model.Sample[T]) T {
+	var p = model.WeightedMean(samples)
+	p = T(math.Min(math.Max(float64(p), model.Epsilon), 1-model.Epsilon))
+	return T(math.Log(float64(p / (1 - p))))
+}
+
+func (Logistic[T]) Loss(y, f T) T {
+	// log(1+exp(f)) - y‧f, computed as max(f,0) + log(1+exp(-|f|)) - y‧f
+	// to avoid overflow of exp(f)
+	var x = float64(f)
+	return T(math.Max(x, 0)+math.Log1p(math.Exp(-math.Abs(x)))) - y*f
+}
+
+func (Logistic[T]) Residual(y, f T) T {
+	return y - sigmoid(f)
+}
+
+// Output returns 1 if probability of label 1 is greater than 0.5, or 0 otherwise
+func (Logistic[T]) Output(f T) T {
+	if f > 0 {
+		return 1
+	}
+	return 0
+}
+
+func sigmoid[T constraints.Float](x T) T {
+	return T(1 / (1 + math.Exp(-float64(x))))
+}
@@ -0,0 +1,55 @@
+package kmeans
+
+import (
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/model"
+)
+
+// modelState is the encoded form of Model
+type modelState[T constraints.Float] struct {
+	K     int                `json:"k"`
+	Means []tensor.Vector[T] `json:"means"`
+}
+
+func (m *Model[T]) state() modelState[T] {
+	return modelState[T]{
+		K:     m.k,
+		Means: m.means,
+	}
+}
+
+func (m *Model[T]) setState(s modelState[T]) {
+	m.k = s.K
+	m.means = s.Means
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *Model[T]) MarshalJSON() ([]byte, error) {
+	return model.EncodeJSON(m.state())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (m *Model[T]) UnmarshalJSON(data []byte) error {
+	var s modelState[T]
+	if err := model.DecodeJSON(data, &s); err != nil {
+		return err
+	}
+	m.setState(s)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (m *Model[T]) MarshalBinary() ([]byte, error) {
+	return model.EncodeBinary(m.state())
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (m *Model[T]) UnmarshalBinary(data []byte) error {
+	var s modelState[T]
+	if err := model.DecodeBinary(data, &s); err != nil {
+		return err
+	}
+	m.setState(s)
+	return nil
+}
@@ -16,6 +16,49 @@ type Options[T constraints.Float] struct {
 	StopError     T
 }
 
+var _ model.Model[float64] = (*Model[float64])(nil)
+
+// Model wraps Clustering as model.Model, it predicts index of the nearest mean
+type Model[T constraints.Float] struct {
+	k       int
+	options *Options[T]
+	means   []tensor.Vector[T]
+}
+
+func NewModel[T constraints.Float](k int, options *Options[T]) *Model[T] {
+	return &Model[T]{
+		k:       k,
+		options: options,
+	}
+}
+
+// Means returns means of clusters
+func (m *Model[T]) Means() []tensor.Vector[T] {
+	return m.means
+}
+
+// Train clusters samples into k clusters, labels of samples are untouched
+func (m *Model[T]) Train(samples []model.Sample[T], tracker model.Tracker) {
+	m.means = Clustering(slices.Clone(samples), m.k, m.options)
+}
+
+// Predict returns index of the nearest mean
+func (m *Model[T]) Predict(x tensor.Vector[T]) T {
+	var min pair.Pair[int, T]
+	for j, y := range m.means {
+		var squared T
+		for k := range x {
+			var d = x[k] - y[k]
+			squared += d * d
+		}
+		if j == 0 || squared < min.Second {
+			min.First = j
+			min.Second = squared
+		}
+	}
+	return T(min.First)
+}
+
 func Clustering[T constraints.Float](samples []model.Sample[T], k int, options *Options[T]) []tensor.Vector[T] {
 	if len(samples) <= k {
 		var means = make([]tensor.Vector[T], len(samples))
@@ -32,7 +75,7 @@ func Clustering[T constraints.Float](samples []model.Sample[T], k int, options *
 		options.StopError = model.Epsilon
 	}
 	var means = slices.Map(slices.ShuffleN(tensor.RangeN(len(samples)), k)[:k], func(i int) tensor.Vector[T] {
-		return samples[i].Attributes
+		return slices.Clone(samples[i].Attributes)
 	})
 	var newMeans = slices.Map(make([]tensor.Vector[T], len(means)), func(_ tensor.Vector[T]) tensor.Vector[T] {
 		return make(tensor.Vector[T], len(samples[0].Attributes))
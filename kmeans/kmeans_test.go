@@ -1,6 +1,7 @@
 package kmeans_test
 
 import (
+	"errors"
 	"math/rand"
 	"testing"
 
@@ -19,6 +20,51 @@ func TestKMean(t *testing.T) {
 		x := label*interval + (rand.Float64()*0.5-0.25)*interval
 		samples[i].Attributes = tensor.Vec(x)
 	}
-	var means = kmeans.Clustering(samples, k)
+	var means = kmeans.Clustering(samples, k, nil)
 	t.Logf("means: %v", means)
 }
+
+func TestModel(t *testing.T) {
+	type T = float64
+	var samples = make([]model.Sample[T], 1<<10)
+	const k = 2
+	for i := range samples {
+		label := T(i % k)
+		x := label*10 + rand.Float64()
+		samples[i].Attributes = tensor.Vec(x)
+		samples[i].Label = -1
+	}
+	var m = kmeans.NewModel[T](k, nil)
+	m.Train(samples, nil)
+	if samples[0].Label != -1 {
+		t.Fatalf("label of samples changed")
+	}
+	for i := 0; i < k; i++ {
+		var x = tensor.Vec(T(i)*10 + 0.5)
+		var mean = m.Means()[int(m.Predict(x))]
+		if mean[0] < T(i)*10 || mean[0] > T(i)*10+1 {
+			t.Fatalf("predict %v: got mean %v", x, mean)
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	type T = float64
+	var samples = make([]model.Sample[T], 1<<10)
+	for i := range samples {
+		samples[i].Attributes = tensor.Vec(T(i%3)*10+rand.Float64(), rand.Float64())
+	}
+	var m = kmeans.NewModel[T](3, nil)
+	m.Train(samples, nil)
+
+	var _, m2 = model.TestEncoding(m, func() *kmeans.Model[T] { return kmeans.NewModel[T](0, nil) }, samples, t)
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal binary error: %v", err)
+	}
+	data[0] = model.EncodingVersion + 1
+	if err := m2.UnmarshalBinary(data); !errors.Is(err, model.ErrUnsupportedVersion) {
+		t.Fatalf("unmarshal future version: got error %v, want %v", err, model.ErrUnsupportedVersion)
+	}
+}
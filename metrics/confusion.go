@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/model"
+)
+
+// ConfusionMatrix counts weights of samples by true label (row) and predicted
+// label (column).
+type ConfusionMatrix[T constraints.Float] struct {
+	Labels []T   // sorted labels seen in true or predicted labels
+	Counts [][]T // Counts[i][j]: weight of samples labeled Labels[i] predicted as Labels[j]
+	index  map[T]int
+}
+
+// NewConfusionMatrix creates confusion matrix of predictions, predictions[i]
+// is predicted label of samples[i].
+func NewConfusionMatrix[T constraints.Float](samples []model.Sample[T], predictions []T) *ConfusionMatrix[T] {
+	checkLength(samples, predictions)
+	var cm = &ConfusionMatrix[T]{
+		index: make(map[T]int),
+	}
+	for i := range samples {
+		cm.index[samples[i].Label] = 0
+		cm.index[predictions[i]] = 0
+	}
+	for label := range cm.index {
+		cm.Labels = append(cm.Labels, label)
+	}
+	sort.Slice(cm.Labels, func(i, j int) bool { return cm.Labels[i] < cm.Labels[j] })
+	cm.Counts = make([][]T, len(cm.Labels))
+	for i, label := range cm.Labels {
+		cm.index[label] = i
+		cm.Counts[i] = make([]T, len(cm.Labels))
+	}
+	for i := range samples {
+		cm.Counts[cm.index[samples[i].Label]][cm.index[predictions[i]]] += model.WeightOf(samples[i])
+	}
+	return cm
+}
+
+// String formats the matrix as a table
+func (cm *ConfusionMatrix[T]) String() string {
+	var sb strings.Builder
+	sb.WriteString("true\\pred")
+	for _, label := range cm.Labels {
+		fmt.Fprintf(&sb, "\t%v", label)
+	}
+	for i, label := range cm.Labels {
+		fmt.Fprintf(&sb, "\n%v", label)
+		for _, count := range cm.Counts[i] {
+			fmt.Fprintf(&sb, "\t%v", count)
+		}
+	}
+	return sb.String()
+}
+
+// counts returns true positive, false positive and false negative of label
+func (cm *ConfusionMatrix[T]) counts(label T) (tp, fp, fn T) {
+	i, ok := cm.index[label]
+	if !ok {
+		return
+	}
+	tp = cm.Counts[i][i]
+	for j := range cm.Labels {
+		if j != i {
+			fp += cm.Counts[j][i]
+			fn += cm.Counts[i][j]
+		}
+	}
+	return
+}
+
+// Total returns total weight of samples
+func (cm *ConfusionMatrix[T]) Total() T {
+	var total T
+	for i := range cm.Counts {
+		for _, count := range cm.Counts[i] {
+			total += count
+		}
+	}
+	return total
+}
+
+// Accuracy returns ratio of samples predicted correctly
+func (cm *ConfusionMatrix[T]) Accuracy() T {
+	var correct T
+	for i := range cm.Counts {
+		correct += cm.Counts[i][i]
+	}
+	return ratio(correct, cm.Total())
+}
+
+// Precision returns precision of label: tp / (tp + fp)
+func (cm *ConfusionMatrix[T]) Precision(label T) T {
+	tp, fp, _ := cm.counts(label)
+	return ratio(tp, tp+fp)
+}
+
+// Recall returns recall of label: tp / (tp + fn)
+func (cm *ConfusionMatrix[T]) Recall(label T) T {
+	tp, _, fn := cm.counts(label)
+	return ratio(tp, tp+fn)
+}
+
+// F1 returns F1 score of label: harmonic mean of precision and recall
+func (cm *ConfusionMatrix[T]) F1(label T) T {
+	tp, fp, fn := cm.counts(label)
+	return ratio(2*tp, 2*tp+fp+fn)
+}
+
+// MacroPrecision returns unweighted mean of precisions of all labels
+func (cm *ConfusionMatrix[T]) MacroPrecision() T {
+	return cm.macro(cm.Precision)
+}
+
+// MacroRecall returns unweighted mean of recalls of all labels
+func (cm *ConfusionMatrix[T]) MacroRecall() T {
+	return cm.macro(cm.Recall)
+}
+
+// MacroF1 returns unweighted mean of F1 scores of all labels
+func (cm *ConfusionMatrix[T]) MacroF1() T {
+	return cm.macro(cm.F1)
+}
+
+func (cm *ConfusionMatrix[T]) macro(f func(T) T) T {
+	if len(cm.Labels) == 0 {
+		return 0
+	}
+	var sum T
+	for _, label := range cm.Labels {
+		sum += f(label)
+	}
+	return sum / T(len(cm.Labels))
+}
+
+// MicroPrecision returns precision computed from counts summed over all labels
+func (cm *ConfusionMatrix[T]) MicroPrecision() T {
+	tp, fp, _ := cm.micro()
+	return ratio(tp, tp+fp)
+}
+
+// MicroRecall returns recall computed from counts summed over all labels
+func (cm *ConfusionMatrix[T]) MicroRecall() T {
+	tp, _, fn := cm.micro()
+	return ratio(tp, tp+fn)
+}
+
+// MicroF1 returns F1 score computed from counts summed over all labels, it
+// equals to accuracy for single-label classification.
+func (cm *ConfusionMatrix[T]) MicroF1() T {
+	tp, fp, fn := cm.micro()
+	return ratio(2*tp, 2*tp+fp+fn)
+}
+
+func (cm *ConfusionMatrix[T]) micro() (tp, fp, fn T) {
+	for _, label := range cm.Labels {
+		a, b, c := cm.counts(label)
+		tp += a
+		fp += b
+		fn += c
+	}
+	return
+}
+
+func ratio[T constraints.Float](a, b T) T {
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
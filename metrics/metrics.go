@@ -0,0 +1,158 @@
+// package metrics implements metrics for classification and regression.
+//
+// Metrics take samples and predictions[i] for samples[i], weights of samples
+// are honored.
+//
+package metrics
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/model"
+)
+
+func checkLength[T constraints.Float](samples []model.Sample[T], predictions []T) {
+	if len(samples) != len(predictions) {
+		panic("metrics: length of samples and predictions mismatched")
+	}
+}
+
+// Accuracy returns ratio of samples predicted correctly
+func Accuracy[T constraints.Float](samples []model.Sample[T], predictions []T) T {
+	checkLength(samples, predictions)
+	var correct, total T
+	for i := range samples {
+		var w = model.WeightOf(samples[i])
+		if predictions[i] == samples[i].Label {
+			correct += w
+		}
+		total += w
+	}
+	return ratio(correct, total)
+}
+
+// MeanSquaredError returns mean of squared errors
+func MeanSquaredError[T constraints.Float](samples []model.Sample[T], predictions []T) T {
+	checkLength(samples, predictions)
+	var sum, total T
+	for i := range samples {
+		var w = model.WeightOf(samples[i])
+		var d = predictions[i] - samples[i].Label
+		sum += w * d * d
+		total += w
+	}
+	return ratio(sum, total)
+}
+
+// MeanAbsoluteError returns mean of absolute errors
+func MeanAbsoluteError[T constraints.Float](samples []model.Sample[T], predictions []T) T {
+	checkLength(samples, predictions)
+	var sum, total T
+	for i := range samples {
+		var w = model.WeightOf(samples[i])
+		sum += w * T(math.Abs(float64(predictions[i]-samples[i].Label)))
+		total += w
+	}
+	return ratio(sum, total)
+}
+
+// R2 returns coefficient of determination: 1 - SSE/SST
+func R2[T constraints.Float](samples []model.Sample[T], predictions []T) T {
+	checkLength(samples, predictions)
+	var mean = model.WeightedMean(samples)
+	var sse, sst T
+	for i := range samples {
+		var w = model.WeightOf(samples[i])
+		var d = predictions[i] - samples[i].Label
+		sse += w * d * d
+		d = samples[i].Label - mean
+		sst += w * d * d
+	}
+	if sst == 0 {
+		if sse == 0 {
+			return 1
+		}
+		return 0
+	}
+	return 1 - sse/sst
+}
+
+// LogLoss returns binary cross entropy, probabilities[i] is predicted
+// probability of samples[i] labeled positive.
+func LogLoss[T constraints.Float](samples []model.Sample[T], probabilities []T, positive T) T {
+	checkLength(samples, probabilities)
+	const eps = 1e-15
+	var sum, total T
+	for i := range samples {
+		var w = model.WeightOf(samples[i])
+		var p = math.Min(math.Max(float64(probabilities[i]), eps), 1-eps)
+		if samples[i].Label != positive {
+			p = 1 - p
+		}
+		sum -= w * T(math.Log(p))
+		total += w
+	}
+	return ratio(sum, total)
+}
+
+// curve returns cumulative true positive and false positive weights at each
+// distinct threshold of scores in descending order.
+func curve[T constraints.Float](samples []model.Sample[T], scores []T, positive T) (tps, fps []T) {
+	checkLength(samples, scores)
+	var indices = make([]int, len(samples))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		return scores[indices[i]] > scores[indices[j]]
+	})
+	var tp, fp T
+	for k, i := range indices {
+		if samples[i].Label == positive {
+			tp += model.WeightOf(samples[i])
+		} else {
+			fp += model.WeightOf(samples[i])
+		}
+		if k+1 == len(indices) || scores[indices[k+1]] != scores[i] {
+			tps = append(tps, tp)
+			fps = append(fps, fp)
+		}
+	}
+	return
+}
+
+// ROCAUC returns area under ROC curve, scores[i] is score of samples[i]
+// being positive, higher score means more likely positive. It returns NaN
+// if samples have no positive or no negative, since the curve is undefined,
+// e.g. for a fold of imbalanced samples.
+func ROCAUC[T constraints.Float](samples []model.Sample[T], scores []T, positive T) T {
+	var tps, fps = curve(samples, scores, positive)
+	if len(tps) == 0 || tps[len(tps)-1] == 0 || fps[len(fps)-1] == 0 {
+		return T(math.NaN())
+	}
+	var area, tp, fp T
+	for i := range tps {
+		area += (fps[i] - fp) * (tps[i] + tp) / 2
+		tp, fp = tps[i], fps[i]
+	}
+	return area / (tp * fp)
+}
+
+// PRAUC returns area under precision-recall curve computed as average
+// precision: Σ(Rᵢ - Rᵢ₋₁)Pᵢ over thresholds.
+func PRAUC[T constraints.Float](samples []model.Sample[T], scores []T, positive T) T {
+	var tps, fps = curve(samples, scores, positive)
+	if len(tps) == 0 || tps[len(tps)-1] == 0 {
+		return 0
+	}
+	var positives = tps[len(tps)-1]
+	var area, recall T
+	for i := range tps {
+		var r = tps[i] / positives
+		area += (r - recall) * tps[i] / (tps[i] + fps[i])
+		recall = r
+	}
+	return area
+}
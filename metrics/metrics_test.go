@@ -0,0 +1,83 @@
+package metrics_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gopherd/ml/metrics"
+	"github.com/gopherd/ml/model"
+)
+
+func labeled(labels ...float64) []model.Sample[float64] {
+	var samples = make([]model.Sample[float64], len(labels))
+	for i, label := range labels {
+		samples[i].Label = label
+	}
+	return samples
+}
+
+func assertNear(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Fatalf("%s: got %v, want %v", name, got, want)
+	}
+}
+
+func TestConfusionMatrix(t *testing.T) {
+	var samples = labeled(0, 0, 0, 1, 1, 2)
+	var predictions = []float64{0, 0, 1, 1, 2, 2}
+	var cm = metrics.NewConfusionMatrix(samples, predictions)
+	t.Logf("\n%v", cm)
+	if len(cm.Labels) != 3 || cm.Counts[0][1] != 1 || cm.Counts[1][2] != 1 {
+		t.Fatalf("unexpected matrix:\n%v", cm)
+	}
+	assertNear(t, "accuracy", cm.Accuracy(), 4.0/6)
+	assertNear(t, "precision(1)", cm.Precision(1), 0.5)
+	assertNear(t, "recall(0)", cm.Recall(0), 2.0/3)
+	assertNear(t, "f1(2)", cm.F1(2), 2.0/3)
+	assertNear(t, "macro precision", cm.MacroPrecision(), (1+0.5+0.5)/3)
+	assertNear(t, "macro recall", cm.MacroRecall(), (2.0/3+0.5+1)/3)
+	assertNear(t, "macro f1", cm.MacroF1(), (0.8+0.5+2.0/3)/3)
+	assertNear(t, "micro f1", cm.MicroF1(), cm.Accuracy())
+	assertNear(t, "micro precision", cm.MicroPrecision(), cm.Accuracy())
+
+	// weight 2 equals to repeated sample
+	samples[2].Weight = 2
+	cm = metrics.NewConfusionMatrix(samples, predictions)
+	assertNear(t, "weighted accuracy", cm.Accuracy(), 4.0/7)
+	assertNear(t, "weighted accuracy", metrics.Accuracy(samples, predictions), 4.0/7)
+}
+
+func TestRegression(t *testing.T) {
+	var samples = labeled(1, 2, 3, 4)
+	var predictions = []float64{1.5, 2, 2, 4}
+	assertNear(t, "mse", metrics.MeanSquaredError(samples, predictions), (0.25+1)/4)
+	assertNear(t, "mae", metrics.MeanAbsoluteError(samples, predictions), 1.5/4)
+	assertNear(t, "r2", metrics.R2(samples, predictions), 1-1.25/5)
+	assertNear(t, "r2 perfect", metrics.R2(samples, []float64{1, 2, 3, 4}), 1)
+}
+
+func TestAUC(t *testing.T) {
+	var samples = labeled(0, 0, 1, 1)
+	var scores = []float64{0.1, 0.4, 0.35, 0.8}
+	assertNear(t, "roc auc", metrics.ROCAUC(samples, scores, 1), 0.75)
+	assertNear(t, "pr auc", metrics.PRAUC(samples, scores, 1), 0.5*1+0.5*2.0/3)
+	assertNear(t, "roc auc perfect", metrics.ROCAUC(samples, []float64{0, 0, 1, 1}, 1), 1)
+	// ties count half
+	assertNear(t, "roc auc ties", metrics.ROCAUC(samples, []float64{0.5, 0.5, 0.5, 0.5}, 1), 0.5)
+	// undefined if one class is absent
+	for _, samples := range [][]model.Sample[float64]{labeled(0, 0), labeled(1, 1), nil} {
+		if auc := metrics.ROCAUC(samples, make([]float64, len(samples)), 1); !math.IsNaN(auc) {
+			t.Fatalf("roc auc of labels %v: got %v, want NaN", samples, auc)
+		}
+	}
+}
+
+func TestLogLoss(t *testing.T) {
+	var samples = labeled(1, 0)
+	var probabilities = []float64{0.8, 0.3}
+	assertNear(t, "log loss", metrics.LogLoss(samples, probabilities, 1), -(math.Log(0.8)+math.Log(0.7))/2)
+	if loss := metrics.LogLoss(samples, []float64{0, 1}, 1); math.IsInf(loss, 0) || loss < 30 {
+		t.Fatalf("log loss of wrong certain predictions should be large but finite: %v", loss)
+	}
+}
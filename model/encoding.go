@@ -0,0 +1,76 @@
+package model
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// EncodingVersion is the current version of encoding format of models, it's
+// increased whenever the format is changed incompatibly.
+const EncodingVersion = 1
+
+// ErrUnsupportedVersion is returned when decoding data encoded by unsupported version
+var ErrUnsupportedVersion = errors.New("model: unsupported encoding version")
+
+type envelope struct {
+	Version int             `json:"version"`
+	Model   json.RawMessage `json:"model"`
+}
+
+func checkVersion(version int) error {
+	if version < 1 || version > EncodingVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+	return nil
+}
+
+// EncodeJSON encodes state of model to JSON with version:
+//
+//	{"version":1,"model":{...}}
+func EncodeJSON(state any) ([]byte, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope{
+		Version: EncodingVersion,
+		Model:   data,
+	})
+}
+
+// DecodeJSON decodes state of model from JSON encoded by EncodeJSON
+func DecodeJSON(data []byte, state any) error {
+	var e envelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	if err := checkVersion(e.Version); err != nil {
+		return err
+	}
+	return json.Unmarshal(e.Model, state)
+}
+
+// EncodeBinary encodes state of model to compact binary format: a version
+// byte followed by gob encoded state.
+func EncodeBinary(state any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(EncodingVersion)
+	if err := gob.NewEncoder(&buf).Encode(state); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeBinary decodes state of model from binary data encoded by EncodeBinary
+func DecodeBinary(data []byte, state any) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%w: empty data", ErrUnsupportedVersion)
+	}
+	if err := checkVersion(int(data[0])); err != nil {
+		return err
+	}
+	return gob.NewDecoder(bytes.NewReader(data[1:])).Decode(state)
+}
@@ -13,12 +13,42 @@ import (
 
 type Sample[T constraints.Float] struct {
 	Attributes tensor.Vector[T]
-	Weight     T
+	Weight     T // weight of sample, zero means default weight 1
 	Label      T
 }
 
+// WeightOf returns weight of the sample, zero weight is treated as 1
+func WeightOf[T constraints.Float](sample Sample[T]) T {
+	if sample.Weight == 0 {
+		return 1
+	}
+	return sample.Weight
+}
+
+// SumWeights computes sum of weights of samples
+func SumWeights[S ~[]Sample[T], T constraints.Float](samples S) T {
+	var sum T
+	for i := range samples {
+		sum += WeightOf(samples[i])
+	}
+	return sum
+}
+
+// Missing returns value which represents a missing attribute, i.e. NaN
+func Missing[T constraints.Float]() T {
+	return T(math.NaN())
+}
+
+// IsMissing reports whether x is a missing value
+func IsMissing[T constraints.Float](x T) bool {
+	return x != x
+}
+
+// Model is the common interface implemented by all learners
 type Model[T constraints.Float] interface {
-	Train(samples []Sample[T], tracker *Tracker)
+	// Train trains the model by samples, tracker is optional and could be nil
+	Train(samples []Sample[T], tracker Tracker)
+	// Predict predicts label for x
 	Predict(x tensor.Vector[T]) T
 }
 
@@ -38,6 +68,18 @@ func Counters[S ~[]Sample[T], T constraints.Float](samples S) map[T]int {
 	return counters
 }
 
+// WeightedCounters computes sum of weights of each class
+func WeightedCounters[S ~[]Sample[T], T constraints.Float](samples S) map[T]T {
+	if len(samples) == 0 {
+		return nil
+	}
+	var counters = make(map[T]T)
+	for i := range samples {
+		counters[samples[i].Label] += WeightOf(samples[i])
+	}
+	return counters
+}
+
 // Entropy computes information entropy for p
 func Entropy[T constraints.Float](p T) T {
 	if p < Epsilon {
@@ -68,16 +110,44 @@ func SumEntropySet[S ~[]Sample[T], T constraints.Float](samples S) T {
 	return SumEntropy(probs)
 }
 
-// Group groups samples by attribute
+// WeightedSumEntropySet computes information entropy of set with weighted samples
+func WeightedSumEntropySet[S ~[]Sample[T], T constraints.Float](samples S) T {
+	if len(samples) == 0 {
+		return 0
+	}
+	var counters = WeightedCounters(samples)
+	var total = SumWeights(samples)
+	var probs = maps.Values(counters)
+	for i := range probs {
+		probs[i] /= total
+	}
+	return SumEntropy(probs)
+}
+
+// Group groups samples by attribute, samples missing the attribute are skipped
 func Group[S ~[]Sample[T], T constraints.Float](samples S, attribute int) map[T]S {
 	var m = make(map[T]S)
 	for _, x := range samples {
 		var attr = x.Attributes[attribute]
+		if IsMissing(attr) {
+			continue
+		}
 		m[attr] = append(m[attr], x)
 	}
 	return m
 }
 
+// Known returns samples which don't miss the attribute
+func Known[S ~[]Sample[T], T constraints.Float](samples S, attribute int) S {
+	var known = make(S, 0, len(samples))
+	for _, x := range samples {
+		if !IsMissing(x.Attributes[attribute]) {
+			known = append(known, x)
+		}
+	}
+	return known
+}
+
 // Gini computes gini index by probablities
 //
 //	g = 1 - Σk(pk^2)
@@ -85,6 +155,50 @@ func Gini[S ~[]T, T constraints.Float](probs S) T {
 	return 1 - slices.SumFunc[S, func(T) T, T, T](probs, mathutil.Square[T])
 }
 
+// WeightedGiniSet computes gini index of set with weighted samples
+func WeightedGiniSet[S ~[]Sample[T], T constraints.Float](samples S) T {
+	if len(samples) == 0 {
+		return 0
+	}
+	var counters = WeightedCounters(samples)
+	var total = SumWeights(samples)
+	var probs = maps.Values(counters)
+	for i := range probs {
+		probs[i] /= total
+	}
+	return Gini(probs)
+}
+
+// WeightedMean computes mean of labels of weighted samples
+func WeightedMean[S ~[]Sample[T], T constraints.Float](samples S) T {
+	var sum, total T
+	for i := range samples {
+		var w = WeightOf(samples[i])
+		sum += w * samples[i].Label
+		total += w
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// WeightedVariance computes variance of labels of weighted samples
+func WeightedVariance[S ~[]Sample[T], T constraints.Float](samples S) T {
+	if len(samples) == 0 {
+		return 0
+	}
+	var mean = WeightedMean(samples)
+	var sum, total T
+	for i := range samples {
+		var w = WeightOf(samples[i])
+		var d = samples[i].Label - mean
+		sum += w * d * d
+		total += w
+	}
+	return sum / total
+}
+
 func Log2(n uint) int {
 	if n < 1 {
 		return 0
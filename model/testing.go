@@ -0,0 +1,51 @@
+package model
+
+import (
+	"encoding"
+	"encoding/json"
+	"testing"
+
+	"github.com/gopherd/doge/constraints"
+)
+
+// Codec is a model which can be encoded and decoded by JSON and binary encoding
+type Codec[T constraints.Float] interface {
+	Model[T]
+	json.Marshaler
+	json.Unmarshaler
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// TestEncoding tests that models created by newModel and decoded from JSON
+// and binary encoding of m predict the same labels as m on samples. It
+// returns the decoded models for further checking.
+func TestEncoding[T constraints.Float, M Codec[T]](m M, newModel func() M, samples []Sample[T], t *testing.T) (fromJSON, fromBinary M) {
+	t.Helper()
+	type codec struct {
+		name      string
+		marshal   func() ([]byte, error)
+		unmarshal func(M, []byte) error
+	}
+	var decoded []M
+	for _, c := range []codec{
+		{"json", m.MarshalJSON, M.UnmarshalJSON},
+		{"binary", m.MarshalBinary, M.UnmarshalBinary},
+	} {
+		data, err := c.marshal()
+		if err != nil {
+			t.Fatalf("%s: marshal error: %v", c.name, err)
+		}
+		var d = newModel()
+		if err := c.unmarshal(d, data); err != nil {
+			t.Fatalf("%s: unmarshal error: %v", c.name, err)
+		}
+		for _, x := range samples {
+			if got, want := d.Predict(x.Attributes), m.Predict(x.Attributes); got != want && !(IsMissing(got) && IsMissing(want)) {
+				t.Fatalf("%s: predict %v: got %v, want %v", c.name, x.Attributes, got, want)
+			}
+		}
+		decoded = append(decoded, d)
+	}
+	return decoded[0], decoded[1]
+}
@@ -0,0 +1,197 @@
+package preprocessing
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/model"
+)
+
+// ErrNotEncodable is returned when steps or model of pipeline can not be
+// encoded or decoded
+var ErrNotEncodable = errors.New("preprocessing: not encodable")
+
+// scalerState is the encoded form of Scaler
+type scalerState[T constraints.Float] struct {
+	Center tensor.Vector[T] `json:"center"`
+	Scale  tensor.Vector[T] `json:"scale"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (s *Scaler[T]) MarshalJSON() ([]byte, error) {
+	return model.EncodeJSON(scalerState[T]{Center: s.center, Scale: s.scale})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *Scaler[T]) UnmarshalJSON(data []byte) error {
+	var state scalerState[T]
+	if err := model.DecodeJSON(data, &state); err != nil {
+		return err
+	}
+	s.center, s.scale = state.Center, state.Scale
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (s *Scaler[T]) MarshalBinary() ([]byte, error) {
+	return model.EncodeBinary(scalerState[T]{Center: s.center, Scale: s.scale})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (s *Scaler[T]) UnmarshalBinary(data []byte) error {
+	var state scalerState[T]
+	if err := model.DecodeBinary(data, &state); err != nil {
+		return err
+	}
+	s.center, s.scale = state.Center, state.Scale
+	return nil
+}
+
+// imputerState is the encoded form of Imputer
+type imputerState[T constraints.Float] struct {
+	Strategy Strategy         `json:"strategy"`
+	Values   tensor.Vector[T] `json:"values"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (im *Imputer[T]) MarshalJSON() ([]byte, error) {
+	return model.EncodeJSON(imputerState[T]{Strategy: im.strategy, Values: im.values})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (im *Imputer[T]) UnmarshalJSON(data []byte) error {
+	var state imputerState[T]
+	if err := model.DecodeJSON(data, &state); err != nil {
+		return err
+	}
+	im.strategy, im.values = state.Strategy, state.Values
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (im *Imputer[T]) MarshalBinary() ([]byte, error) {
+	return model.EncodeBinary(imputerState[T]{Strategy: im.strategy, Values: im.values})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (im *Imputer[T]) UnmarshalBinary(data []byte) error {
+	var state imputerState[T]
+	if err := model.DecodeBinary(data, &state); err != nil {
+		return err
+	}
+	im.strategy, im.values = state.Strategy, state.Values
+	return nil
+}
+
+// Steps and model of pipeline are encoded by themselves and decoded into
+// steps and model of the pipeline, so the decoding pipeline should be created
+// with the same kinds of steps and model.
+
+type pipelineJSONState struct {
+	Steps []json.RawMessage `json:"steps"`
+	Model json.RawMessage   `json:"model"`
+}
+
+type pipelineBinaryState struct {
+	Steps [][]byte
+	Model []byte
+}
+
+// MarshalJSON implements json.Marshaler
+func (p *Pipeline[T]) MarshalJSON() ([]byte, error) {
+	var state = pipelineJSONState{
+		Steps: make([]json.RawMessage, len(p.steps)),
+	}
+	var marshal = func(v any) (json.RawMessage, error) {
+		m, ok := v.(json.Marshaler)
+		if !ok {
+			return nil, ErrNotEncodable
+		}
+		return m.MarshalJSON()
+	}
+	var err error
+	for i, step := range p.steps {
+		if state.Steps[i], err = marshal(step); err != nil {
+			return nil, err
+		}
+	}
+	if state.Model, err = marshal(p.model); err != nil {
+		return nil, err
+	}
+	return model.EncodeJSON(state)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (p *Pipeline[T]) UnmarshalJSON(data []byte) error {
+	var state pipelineJSONState
+	if err := model.DecodeJSON(data, &state); err != nil {
+		return err
+	}
+	if len(state.Steps) != len(p.steps) {
+		return ErrNotEncodable
+	}
+	var unmarshal = func(v any, data []byte) error {
+		u, ok := v.(json.Unmarshaler)
+		if !ok {
+			return ErrNotEncodable
+		}
+		return u.UnmarshalJSON(data)
+	}
+	for i, step := range p.steps {
+		if err := unmarshal(step, state.Steps[i]); err != nil {
+			return err
+		}
+	}
+	return unmarshal(p.model, state.Model)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (p *Pipeline[T]) MarshalBinary() ([]byte, error) {
+	var state = pipelineBinaryState{
+		Steps: make([][]byte, len(p.steps)),
+	}
+	var marshal = func(v any) ([]byte, error) {
+		m, ok := v.(encoding.BinaryMarshaler)
+		if !ok {
+			return nil, ErrNotEncodable
+		}
+		return m.MarshalBinary()
+	}
+	var err error
+	for i, step := range p.steps {
+		if state.Steps[i], err = marshal(step); err != nil {
+			return nil, err
+		}
+	}
+	if state.Model, err = marshal(p.model); err != nil {
+		return nil, err
+	}
+	return model.EncodeBinary(state)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (p *Pipeline[T]) UnmarshalBinary(data []byte) error {
+	var state pipelineBinaryState
+	if err := model.DecodeBinary(data, &state); err != nil {
+		return err
+	}
+	if len(state.Steps) != len(p.steps) {
+		return ErrNotEncodable
+	}
+	var unmarshal = func(v any, data []byte) error {
+		u, ok := v.(encoding.BinaryUnmarshaler)
+		if !ok {
+			return ErrNotEncodable
+		}
+		return u.UnmarshalBinary(data)
+	}
+	for i, step := range p.steps {
+		if err := unmarshal(step, state.Steps[i]); err != nil {
+			return err
+		}
+	}
+	return unmarshal(p.model, state.Model)
+}
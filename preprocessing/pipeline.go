@@ -0,0 +1,86 @@
+package preprocessing
+
+import (
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/container/slices"
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/model"
+)
+
+var _ model.Model[float64] = (*Pipeline[float64])(nil)
+
+// Pipeline chains transformers with a model, transformers are fitted in order
+// on training samples transformed by previous ones, e.g.
+//
+//	preprocessing.NewPipeline[T](svm.NewClassifier[T](1, nil),
+//		preprocessing.NewImputer[T](preprocessing.Median),
+//		preprocessing.NewStandardScaler[T](),
+//	)
+type Pipeline[T constraints.Float] struct {
+	steps []Transformer[T]
+	model model.Model[T]
+}
+
+// NewPipeline creates a pipeline which applies steps before model m
+func NewPipeline[T constraints.Float](m model.Model[T], steps ...Transformer[T]) *Pipeline[T] {
+	return &Pipeline[T]{
+		steps: steps,
+		model: m,
+	}
+}
+
+// Steps returns transformers of the pipeline
+func (p *Pipeline[T]) Steps() []Transformer[T] {
+	return p.steps
+}
+
+// Model returns model of the pipeline
+func (p *Pipeline[T]) Model() model.Model[T] {
+	return p.model
+}
+
+// Fit fits transformers of the pipeline, the model is not trained
+func (p *Pipeline[T]) Fit(samples []model.Sample[T]) {
+	p.fit(samples)
+}
+
+// fit fits transformers and returns transformed samples
+func (p *Pipeline[T]) fit(samples []model.Sample[T]) []model.Sample[T] {
+	for _, step := range p.steps {
+		step.Fit(samples)
+		samples = TransformSamples[T](step, samples)
+	}
+	return samples
+}
+
+// Transform implements Transformer Transform method
+func (p *Pipeline[T]) Transform(x tensor.Vector[T]) tensor.Vector[T] {
+	if len(p.steps) == 0 {
+		return slices.Clone(x)
+	}
+	for _, step := range p.steps {
+		x = step.Transform(x)
+	}
+	return x
+}
+
+// InverseTransform implements Transformer InverseTransform method
+func (p *Pipeline[T]) InverseTransform(x tensor.Vector[T]) tensor.Vector[T] {
+	if len(p.steps) == 0 {
+		return slices.Clone(x)
+	}
+	for i := len(p.steps) - 1; i >= 0; i-- {
+		x = p.steps[i].InverseTransform(x)
+	}
+	return x
+}
+
+// Train fits transformers and trains the model on transformed samples
+func (p *Pipeline[T]) Train(samples []model.Sample[T], tracker model.Tracker) {
+	p.model.Train(p.fit(samples), tracker)
+}
+
+// Predict predicts transformed x by the model
+func (p *Pipeline[T]) Predict(x tensor.Vector[T]) T {
+	return p.model.Predict(p.Transform(x))
+}
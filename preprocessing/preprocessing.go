@@ -0,0 +1,275 @@
+// package preprocessing implements transformers of attributes, e.g. scalers
+// and imputers, and pipeline chaining transformers with a model.
+//
+// Missing values are ignored when fitting and kept by scalers.
+//
+package preprocessing
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/model"
+)
+
+// Transformer learns parameters from training samples and transforms attributes
+type Transformer[T constraints.Float] interface {
+	// Fit learns parameters from samples
+	Fit(samples []model.Sample[T])
+	// Transform returns transformed copy of x
+	Transform(x tensor.Vector[T]) tensor.Vector[T]
+	// InverseTransform returns copy of x transformed back
+	InverseTransform(x tensor.Vector[T]) tensor.Vector[T]
+}
+
+// TransformSamples returns copy of samples with transformed attributes
+func TransformSamples[T constraints.Float](t Transformer[T], samples []model.Sample[T]) []model.Sample[T] {
+	var result = make([]model.Sample[T], len(samples))
+	for i := range samples {
+		result[i] = samples[i]
+		result[i].Attributes = t.Transform(samples[i].Attributes)
+	}
+	return result
+}
+
+// column returns known values of attribute and their weights
+func column[T constraints.Float](samples []model.Sample[T], attr int) (values, weights []T) {
+	for i := range samples {
+		var v = samples[i].Attributes[attr]
+		if !model.IsMissing(v) {
+			values = append(values, v)
+			weights = append(weights, model.WeightOf(samples[i]))
+		}
+	}
+	return
+}
+
+func mean[T constraints.Float](values, weights []T) T {
+	var sum, total T
+	for i, v := range values {
+		sum += weights[i] * v
+		total += weights[i]
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+func std[T constraints.Float](values, weights []T) T {
+	var m = mean(values, weights)
+	var sum, total T
+	for i, v := range values {
+		var d = v - m
+		sum += weights[i] * d * d
+		total += weights[i]
+	}
+	if total == 0 {
+		return 0
+	}
+	return T(math.Sqrt(float64(sum / total)))
+}
+
+// quantile returns weighted q-quantile of values by linear interpolation.
+// Each value is placed at the midpoint of its weight in cumulative weights,
+// normalized so that the first value is at 0 and the last value is at 1, so
+// that the result doesn't change if all weights are scaled by a same factor
+// and it's the same as unweighted quantile if all weights are equal. Values
+// and weights are sorted in place.
+func quantile[T constraints.Float](values, weights []T, q T) T {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Sort(byValue[T]{values, weights})
+	var n = len(values)
+	var total T
+	for _, w := range weights {
+		total += w
+	}
+	var first, last = weights[0] / 2, weights[n-1] / 2
+	var span = total - first - last
+	if span <= 0 {
+		return values[0]
+	}
+	// pos is position of the i-th value, c is sum of weights before it
+	var prev, c T
+	for i, w := range weights {
+		var pos = (c + w/2 - first) / span
+		if q <= pos {
+			if i == 0 || pos == prev {
+				return values[i]
+			}
+			return values[i-1] + (q-prev)/(pos-prev)*(values[i]-values[i-1])
+		}
+		prev = pos
+		c += w
+	}
+	return values[n-1]
+}
+
+// byValue sorts values with their weights
+type byValue[T constraints.Float] struct {
+	values, weights []T
+}
+
+func (s byValue[T]) Len() int           { return len(s.values) }
+func (s byValue[T]) Less(i, j int) bool { return s.values[i] < s.values[j] }
+func (s byValue[T]) Swap(i, j int) {
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+	s.weights[i], s.weights[j] = s.weights[j], s.weights[i]
+}
+
+// Scaler transforms each attribute by (x - center) / scale
+type Scaler[T constraints.Float] struct {
+	fit    func(values, weights []T) (center, scale T)
+	center tensor.Vector[T]
+	scale  tensor.Vector[T]
+}
+
+func newScaler[T constraints.Float](fit func(values, weights []T) (center, scale T)) *Scaler[T] {
+	return &Scaler[T]{fit: fit}
+}
+
+// NewMinMaxScaler creates a scaler which scales attributes into [0, 1]
+func NewMinMaxScaler[T constraints.Float]() *Scaler[T] {
+	return newScaler(func(values, weights []T) (center, scale T) {
+		if len(values) == 0 {
+			return 0, 1
+		}
+		var min, max = values[0], values[0]
+		for _, v := range values {
+			if v < min {
+				min = v
+			} else if v > max {
+				max = v
+			}
+		}
+		return min, max - min
+	})
+}
+
+// NewStandardScaler creates a scaler which standardizes attributes to zero
+// mean and unit variance, weights of samples are honored
+func NewStandardScaler[T constraints.Float]() *Scaler[T] {
+	return newScaler(func(values, weights []T) (center, scale T) {
+		return mean(values, weights), std(values, weights)
+	})
+}
+
+// NewRobustScaler creates a scaler which centers attributes by median and
+// scales them by interquartile range, so that it's robust to outliers,
+// weights of samples are honored
+func NewRobustScaler[T constraints.Float]() *Scaler[T] {
+	return newScaler(func(values, weights []T) (center, scale T) {
+		return quantile(values, weights, 0.5), quantile(values, weights, 0.75) - quantile(values, weights, 0.25)
+	})
+}
+
+// Center returns fitted centers of attributes
+func (s *Scaler[T]) Center() tensor.Vector[T] {
+	return s.center
+}
+
+// Scale returns fitted scales of attributes
+func (s *Scaler[T]) Scale() tensor.Vector[T] {
+	return s.scale
+}
+
+// Fit implements Transformer Fit method, zero scale is replaced by 1
+func (s *Scaler[T]) Fit(samples []model.Sample[T]) {
+	s.center, s.scale = nil, nil
+	if len(samples) == 0 {
+		return
+	}
+	var dim = samples[0].Attributes.Dim()
+	s.center = make(tensor.Vector[T], dim)
+	s.scale = make(tensor.Vector[T], dim)
+	for j := 0; j < dim; j++ {
+		s.center[j], s.scale[j] = s.fit(column(samples, j))
+		if s.scale[j] == 0 {
+			s.scale[j] = 1
+		}
+	}
+}
+
+// Transform implements Transformer Transform method
+func (s *Scaler[T]) Transform(x tensor.Vector[T]) tensor.Vector[T] {
+	var y = make(tensor.Vector[T], len(x))
+	for j, v := range x {
+		y[j] = (v - s.center[j]) / s.scale[j]
+	}
+	return y
+}
+
+// InverseTransform implements Transformer InverseTransform method
+func (s *Scaler[T]) InverseTransform(x tensor.Vector[T]) tensor.Vector[T] {
+	var y = make(tensor.Vector[T], len(x))
+	for j, v := range x {
+		y[j] = v*s.scale[j] + s.center[j]
+	}
+	return y
+}
+
+// Strategy represents strategy of imputation
+type Strategy int
+
+const (
+	Mean   Strategy = iota // replace missing values by weighted mean
+	Median                 // replace missing values by weighted median
+)
+
+// Imputer replaces missing values of attributes
+type Imputer[T constraints.Float] struct {
+	strategy Strategy
+	values   tensor.Vector[T]
+}
+
+// NewImputer creates an imputer by strategy
+func NewImputer[T constraints.Float](strategy Strategy) *Imputer[T] {
+	return &Imputer[T]{strategy: strategy}
+}
+
+// Values returns fitted values for replacing missing values
+func (im *Imputer[T]) Values() tensor.Vector[T] {
+	return im.values
+}
+
+// Fit implements Transformer Fit method, value of attribute without known
+// values is 0
+func (im *Imputer[T]) Fit(samples []model.Sample[T]) {
+	im.values = nil
+	if len(samples) == 0 {
+		return
+	}
+	im.values = make(tensor.Vector[T], samples[0].Attributes.Dim())
+	for j := range im.values {
+		values, weights := column(samples, j)
+		if im.strategy == Median {
+			im.values[j] = quantile(values, weights, 0.5)
+		} else {
+			im.values[j] = mean(values, weights)
+		}
+	}
+}
+
+// Transform implements Transformer Transform method
+func (im *Imputer[T]) Transform(x tensor.Vector[T]) tensor.Vector[T] {
+	var y = make(tensor.Vector[T], len(x))
+	for j, v := range x {
+		if model.IsMissing(v) {
+			v = im.values[j]
+		}
+		y[j] = v
+	}
+	return y
+}
+
+// InverseTransform implements Transformer InverseTransform method, it returns
+// a copy of x since imputed values can not be told from known values
+func (im *Imputer[T]) InverseTransform(x tensor.Vector[T]) tensor.Vector[T] {
+	var y = make(tensor.Vector[T], len(x))
+	copy(y, x)
+	return y
+}
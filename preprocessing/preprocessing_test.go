@@ -0,0 +1,171 @@
+package preprocessing_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/dtree"
+	"github.com/gopherd/ml/dtree/cart"
+	"github.com/gopherd/ml/model"
+	"github.com/gopherd/ml/preprocessing"
+)
+
+func assertVector(t *testing.T, name string, got, want tensor.Vector[float64]) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %v, want %v", name, got, want)
+	}
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > 1e-9 && !(model.IsMissing(got[i]) && model.IsMissing(want[i])) {
+			t.Fatalf("%s: got %v, want %v", name, got, want)
+		}
+	}
+}
+
+var nan = model.Missing[float64]()
+
+var samples = []model.Sample[float64]{
+	{Attributes: tensor.Vec(1.0, 10)},
+	{Attributes: tensor.Vec(2.0, nan)},
+	{Attributes: tensor.Vec(3.0, 30)},
+	{Attributes: tensor.Vec(4.0, 100)},
+	{Attributes: tensor.Vec(5.0, 40)},
+}
+
+func TestScalers(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		scaler *preprocessing.Scaler[float64]
+		center tensor.Vector[float64]
+		scale  tensor.Vector[float64]
+	}{
+		{"minmax", preprocessing.NewMinMaxScaler[float64](), tensor.Vec(1.0, 10), tensor.Vec(4.0, 90)},
+		{"standard", preprocessing.NewStandardScaler[float64](), tensor.Vec(3.0, 45), tensor.Vec(math.Sqrt(2), math.Sqrt(1125))},
+		{"robust", preprocessing.NewRobustScaler[float64](), tensor.Vec(3.0, 35), tensor.Vec(2.0, 55-25)},
+	} {
+		tc.scaler.Fit(samples)
+		assertVector(t, tc.name+" center", tc.scaler.Center(), tc.center)
+		assertVector(t, tc.name+" scale", tc.scaler.Scale(), tc.scale)
+		for _, x := range samples {
+			var y = tc.scaler.Transform(x.Attributes)
+			assertVector(t, tc.name+" inverse", tc.scaler.InverseTransform(y), x.Attributes)
+		}
+	}
+	var s = preprocessing.NewMinMaxScaler[float64]()
+	s.Fit(samples)
+	assertVector(t, "minmax transform", s.Transform(samples[3].Attributes), tensor.Vec(0.75, 1))
+	assertVector(t, "missing kept", s.Transform(samples[1].Attributes), tensor.Vec(0.25, nan))
+}
+
+func TestImputer(t *testing.T) {
+	var mean = preprocessing.NewImputer[float64](preprocessing.Mean)
+	mean.Fit(samples)
+	assertVector(t, "mean", mean.Transform(samples[1].Attributes), tensor.Vec(2.0, 45))
+	var median = preprocessing.NewImputer[float64](preprocessing.Median)
+	median.Fit(samples)
+	assertVector(t, "median", median.Transform(samples[1].Attributes), tensor.Vec(2.0, 35))
+}
+
+func TestWeightedQuantile(t *testing.T) {
+	var weighted = []model.Sample[float64]{
+		{Attributes: tensor.Vec(1.0)},
+		{Attributes: tensor.Vec(2.0)},
+		{Attributes: tensor.Vec(3.0), Weight: 2},
+		{Attributes: tensor.Vec(10.0)},
+	}
+	var s1 = preprocessing.NewRobustScaler[float64]()
+	s1.Fit(weighted)
+	// positions of values are 0, 1/4, 5/8 and 1
+	assertVector(t, "center", s1.Center(), tensor.Vec(8.0/3))
+	assertVector(t, "scale", s1.Scale(), tensor.Vec(16.0/3-2))
+	var im = preprocessing.NewImputer[float64](preprocessing.Median)
+	im.Fit(weighted)
+	assertVector(t, "weighted median", im.Values(), s1.Center())
+
+	// scaling all weights by a same factor changes nothing
+	for _, factor := range []float64{0.1, 0.5, 10} {
+		var scaled = make([]model.Sample[float64], len(weighted))
+		for i, x := range weighted {
+			scaled[i] = x
+			scaled[i].Weight = model.WeightOf(x) * factor
+		}
+		var s2 = preprocessing.NewRobustScaler[float64]()
+		s2.Fit(scaled)
+		assertVector(t, "scaled center", s2.Center(), s1.Center())
+		assertVector(t, "scaled scale", s2.Scale(), s1.Scale())
+	}
+
+	// equal weights are the same as unweighted
+	var equal = make([]model.Sample[float64], len(samples))
+	for i, x := range samples {
+		equal[i] = x
+		equal[i].Weight = 0.1
+	}
+	var s3, s4 = preprocessing.NewRobustScaler[float64](), preprocessing.NewRobustScaler[float64]()
+	s3.Fit(equal)
+	s4.Fit(samples)
+	assertVector(t, "equal center", s3.Center(), s4.Center())
+	assertVector(t, "equal scale", s3.Scale(), s4.Scale())
+}
+
+// recorder records samples it trained on and predicts the first attribute
+type recorder struct {
+	samples []model.Sample[float64]
+}
+
+func (r *recorder) Train(samples []model.Sample[float64], tracker model.Tracker) {
+	r.samples = samples
+}
+
+func (r *recorder) Predict(x tensor.Vector[float64]) float64 {
+	return x[0]
+}
+
+func TestPipeline(t *testing.T) {
+	var r = new(recorder)
+	var p = preprocessing.NewPipeline[float64](r,
+		preprocessing.NewImputer[float64](preprocessing.Median),
+		preprocessing.NewMinMaxScaler[float64](),
+	)
+	p.Train(samples, nil)
+	if !model.IsMissing(samples[1].Attributes[1]) {
+		t.Fatalf("samples changed by pipeline")
+	}
+	assertVector(t, "trained missing", r.samples[1].Attributes, tensor.Vec(0.25, 25.0/90))
+	assertVector(t, "trained max", r.samples[3].Attributes, tensor.Vec(0.75, 1))
+	if got := p.Predict(tensor.Vec(5.0, nan)); got != 1 {
+		t.Fatalf("predict: got %v, want 1", got)
+	}
+	var x = samples[4].Attributes
+	assertVector(t, "pipeline inverse", p.InverseTransform(p.Transform(x)), x)
+}
+
+func clusterSamples(n int) []model.Sample[float64] {
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]model.Sample[float64], n)
+	for i := range samples {
+		var c = float64(i % 2)
+		samples[i].Attributes = tensor.Vec(c*10+r.Float64(), r.NormFloat64()*100)
+		samples[i].Label = c
+	}
+	return samples
+}
+
+func TestPipelineEncoding(t *testing.T) {
+	type T = float64
+	var samples = clusterSamples(200)
+	samples[3].Attributes[1] = nan
+	var newPipeline = func() *preprocessing.Pipeline[T] {
+		return preprocessing.NewPipeline[T](
+			cart.NewModel(dtree.PrePruning, dtree.WithMaxDepth[T](3), dtree.WithContinuous[T](0, 1)),
+			preprocessing.NewImputer[T](preprocessing.Median),
+			preprocessing.NewRobustScaler[T](),
+		)
+	}
+	var p = newPipeline()
+	p.Train(samples, nil)
+
+	model.TestEncoding(p, newPipeline, samples, t)
+}
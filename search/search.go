@@ -0,0 +1,274 @@
+// package search implements hyperparameter search by cross-validation.
+//
+// Candidates are generated from a parameter space by Grid or Random, each
+// candidate is scored by evaluation.CrossValidate on models created by a
+// factory from its parameters.
+//
+package search
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/evaluation"
+	"github.com/gopherd/ml/model"
+)
+
+// Params holds a candidate of parameters by name
+type Params map[string]any
+
+// Value returns value of parameter name as type V
+func Value[V any](params Params, name string) V {
+	return params[name].(V)
+}
+
+// String formats params sorted by name, e.g. "c=1 depth=3"
+func (params Params) String() string {
+	var sb strings.Builder
+	for i, name := range params.names() {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		fmt.Fprintf(&sb, "%s=%v", name, params[name])
+	}
+	return sb.String()
+}
+
+func (params Params) names() []string {
+	var names = make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Space holds candidate values of parameters by name
+type Space map[string][]any
+
+// Grid returns all combinations of values in the space
+func Grid(space Space) []Params {
+	var candidates = []Params{{}}
+	var names = make([]string, 0, len(space))
+	for name := range space {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var next = make([]Params, 0, len(candidates)*len(space[name]))
+		for _, c := range candidates {
+			for _, v := range space[name] {
+				var p = make(Params, len(c)+1)
+				for k, x := range c {
+					p[k] = x
+				}
+				p[name] = v
+				next = append(next, p)
+			}
+		}
+		candidates = next
+	}
+	return candidates
+}
+
+// Distribution samples a value of parameter
+type Distribution func(r *rand.Rand) any
+
+// Choice returns a distribution which samples one of values uniformly
+func Choice(values ...any) Distribution {
+	return func(r *rand.Rand) any {
+		return values[r.Intn(len(values))]
+	}
+}
+
+// Uniform returns a distribution which samples float64 in [min, max)
+func Uniform(min, max float64) Distribution {
+	return func(r *rand.Rand) any {
+		return min + r.Float64()*(max-min)
+	}
+}
+
+// LogUniform returns a distribution which samples float64 in [min, max)
+// uniformly in log scale, e.g. learning rate or C of SVM, min must be positive
+func LogUniform(min, max float64) Distribution {
+	var lmin, lmax = math.Log(min), math.Log(max)
+	return func(r *rand.Rand) any {
+		return math.Exp(lmin + r.Float64()*(lmax-lmin))
+	}
+}
+
+// IntRange returns a distribution which samples int in [min, max]
+func IntRange(min, max int) Distribution {
+	return func(r *rand.Rand) any {
+		return min + r.Intn(max-min+1)
+	}
+}
+
+// Random returns n candidates sampled from distributions by seed
+func Random(distributions map[string]Distribution, n int, seed int64) []Params {
+	var names = make([]string, 0, len(distributions))
+	for name := range distributions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var r = rand.New(rand.NewSource(seed))
+	var candidates = make([]Params, n)
+	for i := range candidates {
+		candidates[i] = make(Params, len(names))
+		for _, name := range names {
+			candidates[i][name] = distributions[name](r)
+		}
+	}
+	return candidates
+}
+
+type options struct {
+	concurrency int
+	minimize    bool
+}
+
+func defaultOptions() options {
+	return options{
+		concurrency: runtime.NumCPU(),
+	}
+}
+
+// Option represents an option of Search
+type Option func(opt *options)
+
+func (opt *options) apply(options []Option) {
+	for _, o := range options {
+		o(opt)
+	}
+	if opt.concurrency < 1 {
+		opt.concurrency = 1
+	}
+}
+
+// WithConcurrency sets maximum number of candidates evaluated concurrently,
+// default is number of CPUs
+func WithConcurrency(n int) Option {
+	return func(opt *options) {
+		opt.concurrency = n
+	}
+}
+
+// WithMinimize sets whether lower score is better, e.g. mean squared error
+func WithMinimize(yes bool) Option {
+	return func(opt *options) {
+		opt.minimize = yes
+	}
+}
+
+// Candidate holds cross-validation result of a candidate
+type Candidate[T constraints.Float] struct {
+	Params Params
+	Rank   int // 1-based rank by mean score of the scoring metric
+	*evaluation.Result[T]
+}
+
+// Result holds results of all candidates
+type Result[T constraints.Float] struct {
+	Scoring    string          // name of metric for ranking
+	Best       Params          // parameters of best candidate
+	BestScore  T               // mean score of best candidate
+	Candidates []*Candidate[T] // candidates in order of input
+}
+
+// String formats candidates as a table sorted by rank
+func (result *Result[T]) String() string {
+	var sorted = make([]*Candidate[T], len(result.Candidates))
+	copy(sorted, result.Candidates)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Rank < sorted[j].Rank })
+	var metrics []string
+	if len(sorted) > 0 {
+		for name := range sorted[0].Mean {
+			metrics = append(metrics, name)
+		}
+		sort.Strings(metrics)
+	}
+	var sb strings.Builder
+	sb.WriteString("rank")
+	for _, name := range metrics {
+		fmt.Fprintf(&sb, "\t%s", name)
+	}
+	sb.WriteString("\tparams")
+	for _, c := range sorted {
+		fmt.Fprintf(&sb, "\n%d", c.Rank)
+		for _, name := range metrics {
+			fmt.Fprintf(&sb, "\t%.4f±%.4f", c.Mean[name], c.Std[name])
+		}
+		fmt.Fprintf(&sb, "\t%v", c.Params)
+	}
+	return sb.String()
+}
+
+// Search scores each candidate by cross-validation on models created by
+// newModel and ranks candidates by mean of metrics[scoring], higher is
+// better unless WithMinimize(true).
+func Search[T constraints.Float](
+	candidates []Params,
+	newModel func(params Params) model.Model[T],
+	samples []model.Sample[T],
+	splitter evaluation.Splitter[T],
+	metrics map[string]evaluation.Metric[T],
+	scoring string,
+	options ...Option,
+) *Result[T] {
+	if _, ok := metrics[scoring]; !ok {
+		panic("search: scoring metric " + scoring + " not found")
+	}
+	var opt = defaultOptions()
+	opt.apply(options)
+	var result = &Result[T]{
+		Scoring:    scoring,
+		Candidates: make([]*Candidate[T], len(candidates)),
+	}
+	var wg sync.WaitGroup
+	var sem = make(chan struct{}, opt.concurrency)
+	for i := range candidates {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			var params = candidates[i]
+			result.Candidates[i] = &Candidate[T]{
+				Params: params,
+				Result: evaluation.CrossValidate(func() model.Model[T] {
+					return newModel(params)
+				}, samples, splitter, metrics, evaluation.WithConcurrency(1)),
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var order = make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		var a, b = result.Candidates[order[i]].Mean[scoring], result.Candidates[order[j]].Mean[scoring]
+		if opt.minimize {
+			return a < b
+		}
+		return a > b
+	})
+	for rank, i := range order {
+		result.Candidates[i].Rank = rank + 1
+	}
+	if len(order) > 0 {
+		var best = result.Candidates[order[0]]
+		result.Best = best.Params
+		result.BestScore = best.Mean[scoring]
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package search_test
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/dtree"
+	"github.com/gopherd/ml/dtree/cart"
+	"github.com/gopherd/ml/evaluation"
+	"github.com/gopherd/ml/gbdt"
+	"github.com/gopherd/ml/model"
+	"github.com/gopherd/ml/search"
+)
+
+func TestGrid(t *testing.T) {
+	var candidates = search.Grid(search.Space{
+		"depth": {1, 2, 3},
+		"leaf":  {1, 5},
+	})
+	if len(candidates) != 6 {
+		t.Fatalf("got %d candidates, want 6", len(candidates))
+	}
+	if s := candidates[0].String(); s != "depth=1 leaf=1" {
+		t.Fatalf("first candidate: got %q", s)
+	}
+	if s := candidates[5].String(); s != "depth=3 leaf=5" {
+		t.Fatalf("last candidate: got %q", s)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	var distributions = map[string]search.Distribution{
+		"rate":  search.LogUniform(0.01, 1),
+		"depth": search.IntRange(1, 4),
+		"loss":  search.Choice("squared", "absolute"),
+	}
+	var candidates = search.Random(distributions, 20, 1)
+	if !reflect.DeepEqual(candidates, search.Random(distributions, 20, 1)) {
+		t.Fatalf("same seed should sample same candidates")
+	}
+	for _, c := range candidates {
+		rate, depth := search.Value[float64](c, "rate"), search.Value[int](c, "depth")
+		if rate < 0.01 || rate >= 1 || depth < 1 || depth > 4 {
+			t.Fatalf("candidate out of range: %v", c)
+		}
+	}
+}
+
+func newSamples(n int) []model.Sample[float64] {
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]model.Sample[float64], n)
+	for i := range samples {
+		x, y := r.Float64(), r.Float64()
+		samples[i].Attributes = tensor.Vec(x, y)
+		samples[i].Label = math.Sin(2*math.Pi*x) + y
+	}
+	return samples
+}
+
+func TestGridSearch(t *testing.T) {
+	type T = float64
+	var samples = newSamples(200)
+	var result = search.Search(search.Grid(search.Space{
+		"depth": {1, 2, 6},
+	}), func(params search.Params) model.Model[T] {
+		return cart.NewRegressionModel(dtree.PrePruning,
+			dtree.WithMaxDepth[T](search.Value[int](params, "depth")),
+			dtree.WithContinuous[T](0, 1),
+		)
+	}, samples, evaluation.KFold[T](4), map[string]evaluation.Metric[T]{
+		"mse": evaluation.MeanSquaredError[T],
+	}, "mse", search.WithMinimize(true))
+	t.Logf("\n%v", result)
+	if depth := search.Value[int](result.Best, "depth"); depth != 6 {
+		t.Fatalf("best depth: got %d, want 6", depth)
+	}
+	if len(result.Candidates) != 3 || result.Candidates[0].Rank != 3 {
+		t.Fatalf("unexpected candidates:\n%v", result)
+	}
+}
+
+func TestRandomSearch(t *testing.T) {
+	type T = float64
+	var samples = newSamples(200)
+	var result = search.Search(search.Random(map[string]search.Distribution{
+		"rate": search.LogUniform(0.001, 0.5),
+	}, 6, 1), func(params search.Params) model.Model[T] {
+		return gbdt.NewModel[T](gbdt.Squared[T]{}, 20,
+			gbdt.WithLearningRate(T(search.Value[float64](params, "rate"))),
+		)
+	}, samples, evaluation.KFold[T](3), map[string]evaluation.Metric[T]{
+		"mse": evaluation.MeanSquaredError[T],
+	}, "mse", search.WithMinimize(true))
+	t.Logf("\n%v", result)
+	for _, c := range result.Candidates {
+		if c.Mean["mse"] < result.BestScore {
+			t.Fatalf("candidate %v scores %v better than best %v", c.Params, c.Mean["mse"], result.BestScore)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package svm
+
+import (
+	"container/list"
+	"unsafe"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/tensor"
+)
+
+// DefaultCacheSize is the default memory budget of kernel cache in bytes
+const DefaultCacheSize = 100 << 20
+
+type cacheRow[T constraints.Float] struct {
+	index  int
+	values tensor.Vector[T]
+}
+
+// cache is a LRU cache of kernel rows, row i holds k(xᵢ,xₜ) for all t
+type cache[T constraints.Float] struct {
+	capacity int
+	size     int // length of row
+	rows     map[int]*list.Element
+	lru      *list.List
+}
+
+// newCache creates a cache holding rows of given size within budget bytes,
+// it holds at least 2 rows which are required by an SMO iteration
+func newCache[T constraints.Float](size, budget int) *cache[T] {
+	var capacity = 2
+	if size > 0 {
+		if n := budget / (size * int(unsafe.Sizeof(T(0)))); n > capacity {
+			capacity = n
+		}
+	}
+	return &cache[T]{
+		capacity: capacity,
+		size:     size,
+		rows:     make(map[int]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// get returns row i and reports whether it's cached, the row should be
+// filled by caller if not cached
+func (c *cache[T]) get(i int) (tensor.Vector[T], bool) {
+	if e, ok := c.rows[i]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*cacheRow[T]).values, true
+	}
+	var row *cacheRow[T]
+	if c.lru.Len() < c.capacity {
+		row = &cacheRow[T]{values: make(tensor.Vector[T], c.size)}
+		c.rows[i] = c.lru.PushFront(row)
+	} else {
+		// reuse the least recently used row
+		var e = c.lru.Back()
+		row = e.Value.(*cacheRow[T])
+		delete(c.rows, row.index)
+		c.lru.MoveToFront(e)
+		c.rows[i] = e
+	}
+	row.index = i
+	return row.values, false
+}
@@ -3,18 +3,97 @@
 package svm
 
 import (
-	"math/rand"
+	"math"
 
 	"github.com/gopherd/doge/constraints"
-	"github.com/gopherd/doge/container/pair"
+	"github.com/gopherd/doge/container/slices"
 	"github.com/gopherd/doge/math/mathutil"
 	"github.com/gopherd/doge/math/tensor"
-	"github.com/gopherd/doge/operator"
 	"github.com/gopherd/ml/canvas2d"
 	"github.com/gopherd/ml/model"
 )
 
-// linear classifier: f(x) = Σᵢ(aᵢ‧k(x,xᵢ)) + b
+// DefaultTolerance is the default tolerance of KKT violation
+const DefaultTolerance = 1e-3
+
+// tau replaces non-positive curvature of non-PSD kernels
+const tau = 1e-12
+
+// snapshotInterval is number of iterations between two snapshots
+const snapshotInterval = 100
+
+type options struct {
+	tolerance     float64
+	maxIterations int
+	cacheSize     int
+	gram          any // *gram[T]
+}
+
+func defaultOptions() options {
+	return options{
+		tolerance: DefaultTolerance,
+		cacheSize: DefaultCacheSize,
+	}
+}
+
+// Option represents an option of svm
+type Option func(opt *options)
+
+func (opt *options) apply(options []Option) {
+	for _, o := range options {
+		o(opt)
+	}
+}
+
+// WithTolerance sets tolerance of KKT violation used as stopping criterion,
+// default is DefaultTolerance
+func WithTolerance(tolerance float64) Option {
+	return func(opt *options) {
+		opt.tolerance = tolerance
+	}
+}
+
+// WithMaxIterations sets maximum number of SMO iterations, default is
+// max(10⁷, 100n) where n is number of samples
+func WithMaxIterations(n int) Option {
+	return func(opt *options) {
+		opt.maxIterations = n
+	}
+}
+
+// WithCacheSize sets memory budget of kernel row cache in bytes, default is
+// DefaultCacheSize, at least 2 rows are cached
+func WithCacheSize(bytes int) Option {
+	return func(opt *options) {
+		opt.cacheSize = bytes
+	}
+}
+
+// WithGram sets precomputed Gram matrix of samples: gram[i][j] = k(xᵢ,xⱼ),
+// it's used instead of evaluating kernel while training. Samples are
+// identified by their attributes (the underlying array, not the values), so
+// the matrix also serves subsets of samples, e.g. binary problems of
+// Multiclass or folds of cross validation. Kernel is evaluated for samples
+// not found, and it's still required by Predict.
+//
+// Samples whose attributes are copied, e.g. by preprocessing.TransformSamples
+// or inside preprocessing.Pipeline, are not found even if values are equal,
+// the matrix should be computed from the transformed samples instead.
+// Classifier.GramMisses reports number of training samples not found.
+//
+// It panics if gram is not a square matrix of samples, and NewClassifier
+// panics if T mismatched.
+func WithGram[T constraints.Float](samples []model.Sample[T], gram [][]T) Option {
+	var g = newGram(samples, gram)
+	return func(opt *options) {
+		opt.gram = g
+	}
+}
+
+var _ model.Model[float64] = (*Classifier[float64])(nil)
+
+// binary classifier: f(x) = Σᵢ(aᵢ‧yᵢ‧k(x,xᵢ)) + b, labels are mapped by
+// sign to -1 and +1, use Multiclass for more classes
 type Classifier[T constraints.Float] struct {
 	// len(a) == len(s), s=[(x,y)]
 	a tensor.Vector[T]
@@ -23,14 +102,33 @@ type Classifier[T constraints.Float] struct {
 	k Kernel[T]
 	c T
 
-	min, max tensor.Vector[T]
+	// w = Σᵢ(aᵢ‧yᵢ‧xᵢ) for linear kernel
+	w tensor.Vector[T]
+
+	options    options
+	gram       *gram[T]
+	gramMisses int
+	iterations int
+	min, max   tensor.Vector[T]
 }
 
-func NewClassifier[T constraints.Float](c T, kernel Kernel[T]) *Classifier[T] {
-	return &Classifier[T]{
-		k: kernel,
-		c: c,
+// NewClassifier creates a classifier with penalty c and kernel, c <= 0 means
+// hard margin, and nil kernel means Linear kernel
+func NewClassifier[T constraints.Float](c T, kernel Kernel[T], options ...Option) *Classifier[T] {
+	var classifier = &Classifier[T]{
+		k:       kernel,
+		c:       c,
+		options: defaultOptions(),
+	}
+	classifier.options.apply(options)
+	if classifier.options.gram != nil {
+		g, ok := classifier.options.gram.(*gram[T])
+		if !ok {
+			panic("svm: type of gram matrix mismatched")
+		}
+		classifier.gram = g
 	}
+	return classifier
 }
 
 func (c *Classifier[T]) kernel(x, y tensor.Vector[T]) T {
@@ -40,6 +138,25 @@ func (c *Classifier[T]) kernel(x, y tensor.Vector[T]) T {
 	return c.k(x, y)
 }
 
+// bound returns upper bound of a[i]: c scaled by weight of i-th sample
+func (c *Classifier[T]) bound(i int) T {
+	if c.c <= 0 {
+		return T(math.Inf(1))
+	}
+	return c.c * model.WeightOf(c.s[i])
+}
+
+// Iterations returns number of SMO iterations of last training
+func (c *Classifier[T]) Iterations() int {
+	return c.iterations
+}
+
+// GramMisses returns number of samples of last training which are not found
+// in Gram matrix set by WithGram, kernel is evaluated for them
+func (c *Classifier[T]) GramMisses() int {
+	return c.gramMisses
+}
+
 func (c *Classifier[T]) Snapshot() *canvas2d.Image {
 	if c.k != nil || len(c.s) == 0 || c.s[0].Attributes.Dim() != 2 {
 		return nil
@@ -56,8 +173,9 @@ func (c *Classifier[T]) Snapshot() *canvas2d.Image {
 		// draw line: ax + by + c = 0
 		var a, b T
 		for i := range c.a {
-			a += c.a[i] * c.s[i].Attributes[0]
-			b += c.a[i] * c.s[i].Attributes[1]
+			y := sign(c.s[i].Label)
+			a += c.a[i] * y * c.s[i].Attributes[0]
+			b += c.a[i] * y * c.s[i].Attributes[1]
 		}
 		x0, y0, x1, y1, ok := canvas2d.ClipSegment(a, b, c.b, c.min[0], c.max[0], c.min[1], c.max[1])
 		if ok {
@@ -73,86 +191,268 @@ func (c *Classifier[T]) Snapshot() *canvas2d.Image {
 
 func (c *Classifier[T]) Train(samples []model.Sample[T], tracker model.Tracker) {
 	c.min, c.max = model.Minmax(samples)
-	c.s = samples
+	// support vectors are compacted in place, so keep samples untouched
+	c.s = slices.Clone(samples)
 	c.a = make([]T, len(c.s))
-	for i := range c.a {
-		c.a[i] = T(rand.Float64()) * c.c
-	}
+	c.b = 0
+	c.w = nil
+	c.iterations = 0
 
 	if tracker != nil {
 		tracker.Snapshot(c.Snapshot())
 	}
 
-	c.smo(samples, tracker)
+	c.smo(tracker)
 
 	if tracker != nil {
 		tracker.Snapshot(c.Snapshot())
 	}
 }
 
-// implements SMO algorithm
-func (c *Classifier[T]) smo(samples []model.Sample[T], tracker model.Tracker) {
-	const maxIdle = 16
-	var idle int
-	for idle < maxIdle {
-		idle++
-		// step1: select index pair (pi, pj)
-		pi, pj := c.selectAlpha()
-		if pi.First < 0 {
-			break
+// solver solves the dual problem:
+//
+//	min ½‧Σᵢⱼ(aᵢ‧aⱼ‧Qᵢⱼ) - Σᵢaᵢ, s.t. Σᵢ(aᵢ‧yᵢ) = 0, 0 ≤ aᵢ ≤ Cᵢ
+//
+// where Qᵢⱼ = yᵢ‧yⱼ‧k(xᵢ,xⱼ), and g is the gradient Q‧a - 1
+type solver[T constraints.Float] struct {
+	c     *Classifier[T]
+	y     tensor.Vector[T]
+	g     tensor.Vector[T]
+	qd    tensor.Vector[T]
+	ub    tensor.Vector[T]
+	gram  []int // indices of samples in Gram matrix
+	cache *cache[T]
+}
+
+func newSolver[T constraints.Float](c *Classifier[T]) *solver[T] {
+	var n = len(c.s)
+	var s = &solver[T]{
+		c:  c,
+		y:  make(tensor.Vector[T], n),
+		g:  make(tensor.Vector[T], n),
+		qd: make(tensor.Vector[T], n),
+		ub: make(tensor.Vector[T], n),
+	}
+	s.cache = newCache[T](n, c.options.cacheSize)
+	c.gramMisses = 0
+	if c.gram != nil {
+		s.gram = make([]int, n)
+		for i := range c.s {
+			s.gram[i] = c.gram.index(c.s[i])
+			if s.gram[i] < 0 {
+				c.gramMisses++
+			}
+		}
+	}
+	for i := range c.s {
+		s.y[i] = sign(c.s[i].Label)
+		s.g[i] = -1
+		s.qd[i] = s.kernel(i, i)
+		s.ub[i] = c.bound(i)
+	}
+	return s
+}
+
+// kernel returns k(xᵢ,xⱼ) from Gram matrix if possible
+func (s *solver[T]) kernel(i, j int) T {
+	if s.gram != nil {
+		if gi, gj := s.gram[i], s.gram[j]; gi >= 0 && gj >= 0 {
+			return s.c.gram.values[gi][gj]
 		}
-		i, j := pi.First, pj.First
-
-		// step2: update a[i], a[j]
-		ai, aj := c.a[i], c.a[j]
-		xi, xj := c.s[i].Attributes, c.s[j].Attributes
-		yi, yj := sign(c.s[i].Label), sign(c.s[j].Label)
-		ei, ej := mathutil.Abs(yi-pi.Second), mathutil.Abs(yj-pj.Second)
-		kii, kjj, kij := c.kernel(xi, xi), c.kernel(xj, xj), c.kernel(xi, xj)
-		eta := kii + kjj - 2*kij
-		c.a[j] = c.a[j] + yj*(ei-ej)/eta
-		if c.c > 0 {
-			u := operator.If(yi*yj < 0, mathutil.Max(0, aj-ai), mathutil.Max(0, ai+aj-c.c))
-			v := operator.If(yi*yj < 0, mathutil.Min(c.c, aj-ai+c.c), mathutil.Min(c.c, ai+aj))
-			c.a[j] = mathutil.Clamp(c.a[j], u, v)
-		}
-		c.a[i] += yi * yj * (aj - c.a[j])
-		if mathutil.Abs(c.a[i]-ai) < model.Epsilon && mathutil.Abs(c.a[j]-aj) < model.Epsilon {
+	}
+	return s.c.kernel(s.c.s[i].Attributes, s.c.s[j].Attributes)
+}
+
+// kernelRow returns row i: k(xᵢ,xₜ) for all t
+func (s *solver[T]) kernelRow(i int) tensor.Vector[T] {
+	row, ok := s.cache.get(i)
+	if !ok {
+		for t := range s.c.s {
+			row[t] = s.kernel(i, t)
+		}
+	}
+	return row
+}
+
+func (s *solver[T]) isUp(t int) bool {
+	var a = s.c.a[t]
+	return (s.y[t] > 0 && a < s.ub[t]) || (s.y[t] < 0 && a > 0)
+}
+
+func (s *solver[T]) isLow(t int) bool {
+	var a = s.c.a[t]
+	return (s.y[t] > 0 && a > 0) || (s.y[t] < 0 && a < s.ub[t])
+}
+
+// selectWorkingSet selects the pair (i, j) by second order information
+// (WSS3 of Fan, Chen and Lin), it returns -1 if the maximal KKT violation
+// is less than tolerance
+func (s *solver[T]) selectWorkingSet(tolerance T) (i, j int) {
+	var gmax, gmax2 = T(math.Inf(-1)), T(math.Inf(-1))
+	i = -1
+	for t := range s.y {
+		if s.isUp(t) {
+			if v := -s.y[t] * s.g[t]; v >= gmax {
+				gmax = v
+				i = t
+			}
+		}
+	}
+	if i < 0 {
+		return -1, -1
+	}
+	var ki = s.kernelRow(i)
+	var minObj = T(math.Inf(1))
+	j = -1
+	for t := range s.y {
+		if !s.isLow(t) {
 			continue
 		}
+		var yg = s.y[t] * s.g[t]
+		if yg > gmax2 {
+			gmax2 = yg
+		}
+		var diff = gmax + yg
+		if diff <= 0 {
+			continue
+		}
+		var quad = s.qd[i] + s.qd[t] - 2*ki[t]
+		if quad <= 0 {
+			quad = tau
+		}
+		if obj := -diff * diff / quad; obj <= minObj {
+			minObj = obj
+			j = t
+		}
+	}
+	if gmax+gmax2 < tolerance || j < 0 {
+		return -1, -1
+	}
+	return i, j
+}
 
-		// step3: update bias
-		var n int
-		var b T
-		var bi = -ei + ai*yi*kii + aj*yj*kij + c.b
-		var bj = -ej - (c.a[i]-ai)*yi*kij - (c.a[j]-aj)*yj*kjj + c.b
-		if c.a[i] > model.Epsilon && c.a[i] < c.c-model.Epsilon {
-			n++
-			b += bi
+// update solves the two variables sub-problem of (i, j) analytically and
+// updates the gradient
+func (s *solver[T]) update(i, j int) {
+	var a = s.c.a
+	var ki, kj = s.kernelRow(i), s.kernelRow(j)
+	var ci, cj = s.ub[i], s.ub[j]
+	var ai, aj = a[i], a[j]
+	var quad = s.qd[i] + s.qd[j] - 2*ki[j]
+	if quad <= 0 {
+		quad = tau
+	}
+	if s.y[i] != s.y[j] {
+		delta := (-s.g[i] - s.g[j]) / quad
+		diff := a[i] - a[j]
+		a[i] += delta
+		a[j] += delta
+		if diff > 0 {
+			if a[j] < 0 {
+				a[j], a[i] = 0, diff
+			}
+		} else if a[i] < 0 {
+			a[i], a[j] = 0, -diff
 		}
-		if c.a[j] > model.Epsilon && c.a[j] < c.c-model.Epsilon {
-			n++
-			b += bj
+		if diff > ci-cj {
+			if a[i] > ci {
+				a[i], a[j] = ci, ci-diff
+			}
+		} else if a[j] > cj {
+			a[j], a[i] = cj, cj+diff
 		}
-		if n == 0 {
-			n = 2
-			b = bi + bj
+	} else {
+		delta := (s.g[i] - s.g[j]) / quad
+		sum := a[i] + a[j]
+		a[i] -= delta
+		a[j] += delta
+		if sum > ci {
+			if a[i] > ci {
+				a[i], a[j] = ci, sum-ci
+			}
+		} else if a[j] < 0 {
+			a[j], a[i] = 0, sum
+		}
+		if sum > cj {
+			if a[j] > cj {
+				a[j], a[i] = cj, sum-cj
+			}
+		} else if a[i] < 0 {
+			a[i], a[j] = 0, sum
+		}
+	}
+	var di, dj = (a[i] - ai) * s.y[i], (a[j] - aj) * s.y[j]
+	for t := range s.g {
+		s.g[t] += s.y[t] * (ki[t]*di + kj[t]*dj)
+	}
+}
+
+// bias computes b by averaging yᵢ‧gᵢ of free variables, or by middle of
+// the feasible interval if there are no free variables
+func (s *solver[T]) bias() T {
+	var ub, lb = T(math.Inf(1)), T(math.Inf(-1))
+	var sum T
+	var free int
+	for t, a := range s.c.a {
+		var yg = s.y[t] * s.g[t]
+		switch {
+		case a >= s.ub[t]:
+			if s.y[t] < 0 {
+				ub = mathutil.Min(ub, yg)
+			} else {
+				lb = mathutil.Max(lb, yg)
+			}
+		case a <= 0:
+			if s.y[t] > 0 {
+				ub = mathutil.Min(ub, yg)
+			} else {
+				lb = mathutil.Max(lb, yg)
+			}
+		default:
+			free++
+			sum += yg
 		}
-		c.b = b / T(n)
-		idle = 0
-		if tracker != nil {
+	}
+	if free > 0 {
+		return -sum / T(free)
+	}
+	// one of the bounds is infinite only if all samples have the same label
+	switch {
+	case math.IsInf(float64(lb), -1):
+		return -ub
+	case math.IsInf(float64(ub), 1):
+		return -lb
+	}
+	return -(ub + lb) / 2
+}
+
+// implements SMO algorithm
+func (c *Classifier[T]) smo(tracker model.Tracker) {
+	if len(c.s) == 0 {
+		return
+	}
+	var maxIterations = c.options.maxIterations
+	if maxIterations <= 0 {
+		maxIterations = mathutil.Max(10000000, 100*len(c.s))
+	}
+	var tolerance = T(c.options.tolerance)
+	var s = newSolver(c)
+	for c.iterations < maxIterations {
+		i, j := s.selectWorkingSet(tolerance)
+		if i < 0 {
+			break
+		}
+		s.update(i, j)
+		c.iterations++
+		if tracker != nil && c.iterations%snapshotInterval == 0 {
+			c.b = s.bias()
 			tracker.Snapshot(c.Snapshot())
 		}
 	}
+	c.b = s.bias()
 
 	// remove zeros from a and save relative samples(support vectors)
 	var n int
-	for i := range c.a {
-		if c.a[i] > 0 {
-			n++
-		}
-	}
-	n = 0
 	for i := range c.a {
 		if c.a[i] > 0 {
 			c.a[n] = c.a[i]
@@ -162,55 +462,38 @@ func (c *Classifier[T]) smo(samples []model.Sample[T], tracker model.Tracker) {
 	}
 	c.a = c.a[:n]
 	c.s = c.s[:n]
-
-	c.updateBias()
+	c.updateWeights()
 }
 
-func (c *Classifier[T]) selectAlpha() (max, far pair.Pair[int, T]) {
-	if len(c.a) < 2 {
-		max.First = -1
+// updateWeights computes w of linear kernel, so that Decision takes O(d)
+// instead of O(n‧d)
+func (c *Classifier[T]) updateWeights() {
+	c.w = nil
+	if c.k != nil || len(c.s) == 0 {
 		return
 	}
-	max.First = rand.Intn(len(c.a))
-	max.Second = c.Predict(c.s[max.First].Attributes)
-	var farDist T
-	far.First = -1
+	c.w = make(tensor.Vector[T], c.s[0].Attributes.Dim())
 	for i := range c.a {
-		if i == max.First {
-			continue
-		}
-		var dist T
+		var ay = c.a[i] * sign(c.s[i].Label)
 		for j, x := range c.s[i].Attributes {
-			dx := c.s[max.First].Attributes[j] - x
-			dist += dx * dx
-		}
-		if far.First < 0 || dist > farDist {
-			far.First = i
-			farDist = dist
+			c.w[j] += ay * x
 		}
 	}
-	far.Second = c.Predict(c.s[far.First].Attributes)
-	return max, far
 }
 
-func (c *Classifier[T]) updateBias() {
-	if len(c.s) == 0 {
-		return
+// Decision returns decision value f(x), whose sign is the predicted label
+// and magnitude is proportional to the distance to the hyperplane
+func (c *Classifier[T]) Decision(x tensor.Vector[T]) T {
+	if c.w != nil {
+		return c.w.Dot(x) + c.b
 	}
-	var sum T
-	for i := range c.s {
-		sum += sign(c.s[i].Label)
-		for j := range c.s {
-			sum -= c.a[j] * sign(c.s[j].Label) * c.kernel(c.s[i].Attributes, c.s[j].Attributes)
-		}
+	var sum = c.b
+	for i := range c.a {
+		sum += c.a[i] * sign(c.s[i].Label) * c.kernel(x, c.s[i].Attributes)
 	}
-	c.b = sum / T(len(c.s))
+	return sum
 }
 
 func (c *Classifier[T]) Predict(x tensor.Vector[T]) T {
-	var sum = c.b
-	for i := range c.a {
-		sum += c.a[i] * c.kernel(x, c.s[i].Attributes)
-	}
-	return sign(sum)
+	return sign(c.Decision(x))
 }
@@ -1,16 +1,20 @@
 package svm_test
 
 import (
+	"math"
 	"math/rand"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gopherd/doge/container/slices"
 	"github.com/gopherd/doge/math/mathutil"
 	"github.com/gopherd/doge/math/tensor"
 	"github.com/gopherd/doge/operator"
 	"github.com/gopherd/ml/canvas2d"
+	"github.com/gopherd/ml/metrics"
 	"github.com/gopherd/ml/model"
+	"github.com/gopherd/ml/preprocessing"
 	"github.com/gopherd/ml/svm"
 )
 
@@ -30,8 +34,11 @@ func TestSVMClassifier(t *testing.T) {
 	var model = svm.NewClassifier[T](1.0, nil)
 	var tracker = canvas2d.NewAnimation()
 	model.Train(samples, tracker)
+	var errors int
 	for i := range samples {
-		samples[i].Label = model.Predict(samples[i].Attributes)
+		if model.Predict(samples[i].Attributes) != samples[i].Label {
+			errors++
+		}
 	}
 	tracker.Snapshot(nil)
 
@@ -44,4 +51,334 @@ func TestSVMClassifier(t *testing.T) {
 		tracker.Encode(file)
 	}
 	t.Log(tracker.String())
+	t.Logf("iterations: %d, errors: %d", model.Iterations(), errors)
+	if errors > 2 {
+		t.Fatalf("too many errors on separable samples: %d", errors)
+	}
+}
+
+func TestSolver(t *testing.T) {
+	type T = float64
+	// hard margin solution: w = (1,1), b = -1
+	var samples = []model.Sample[T]{
+		{Attributes: tensor.Vec[T](0, 0), Label: -1},
+		{Attributes: tensor.Vec[T](1, 1), Label: 1},
+		{Attributes: tensor.Vec[T](2, 0), Label: 1},
+	}
+	var c = svm.NewClassifier[T](0, nil, svm.WithTolerance(1e-6))
+	c.Train(samples, nil)
+	for _, tc := range []struct {
+		x    tensor.Vector[T]
+		want T
+	}{
+		{tensor.Vec[T](0, 0), -1},
+		{tensor.Vec[T](1, 1), 1},
+		{tensor.Vec[T](2, 0), 1},
+		{tensor.Vec[T](3, 3), 5},
+		{tensor.Vec[T](0.5, 0.5), 0},
+	} {
+		if got := c.Decision(tc.x); math.Abs(got-tc.want) > 1e-4 {
+			t.Fatalf("decision %v: got %v, want %v", tc.x, got, tc.want)
+		}
+	}
+
+	// results are deterministic and limited by max iterations
+	var r = rand.New(rand.NewSource(1))
+	samples = circles(200, r)
+	var c1 = svm.NewClassifier[T](1, svm.RBF[T](1))
+	var c2 = svm.NewClassifier[T](1, svm.RBF[T](1))
+	c1.Train(samples, nil)
+	c2.Train(samples, nil)
+	for _, x := range samples {
+		if d1, d2 := c1.Decision(x.Attributes), c2.Decision(x.Attributes); d1 != d2 {
+			t.Fatalf("decision %v: got %v and %v", x.Attributes, d1, d2)
+		}
+	}
+	var c3 = svm.NewClassifier[T](1, svm.RBF[T](1), svm.WithMaxIterations(10))
+	c3.Train(samples, nil)
+	if c1.Iterations() <= 10 || c3.Iterations() != 10 {
+		t.Fatalf("iterations: got %d and %d", c1.Iterations(), c3.Iterations())
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	type T = float64
+	var samples = slices.Map(tensor.RangeN(50), func(i int) model.Sample[T] {
+		x := T(rand.Float64())
+		y := T(rand.Float64())
+		return model.Sample[T]{
+			Attributes: tensor.Vec(x, y),
+			Label:      operator.If(x < y, 1.0, -1.0),
+		}
+	})
+	var c = svm.NewClassifier[T](1.0, nil)
+	c.Train(samples, nil)
+
+	model.TestEncoding(c, func() *svm.Classifier[T] { return svm.NewClassifier[T](0, nil) }, samples, t)
+}
+
+func TestKernels(t *testing.T) {
+	type T = float64
+	var x, y = tensor.Vec[T](1, 2), tensor.Vec[T](3, 1)
+	for _, tc := range []struct {
+		name   string
+		kernel svm.Kernel[T]
+		want   T
+	}{
+		{"linear", svm.Linear[T](), 5},
+		{"rbf", svm.RBF[T](0.5), math.Exp(-2.5)},
+		{"polynomial", svm.Polynomial[T](2, 0.5, 1), 12.25},
+		{"sigmoid", svm.Sigmoid[T](0.1, -0.5), math.Tanh(0)},
+		{"laplacian", svm.Laplacian[T](0.5), math.Exp(-1.5)},
+		{"chi-squared", svm.ChiSquared[T](1), math.Exp(-(1 + 1.0/3))},
+	} {
+		if got := tc.kernel(x, y); math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+		if got := tc.kernel(y, x); math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("%s: not symmetric: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// xor returns samples labeled by sign of x‧y, which are not linearly separable
+func xor(n int, r *rand.Rand) []model.Sample[float64] {
+	return slices.Map(tensor.RangeN(n), func(i int) model.Sample[float64] {
+		x := r.Float64()*2 - 1
+		y := r.Float64()*2 - 1
+		for mathutil.Abs(x) < 0.1 || mathutil.Abs(y) < 0.1 {
+			x = r.Float64()*2 - 1
+			y = r.Float64()*2 - 1
+		}
+		return model.Sample[float64]{
+			Attributes: tensor.Vec(x, y),
+			Label:      operator.If(x*y > 0, 1.0, -1.0),
+		}
+	})
+}
+
+// circles returns samples on two concentric circles labeled by circle
+func circles(n int, r *rand.Rand) []model.Sample[float64] {
+	return slices.Map(tensor.RangeN(n), func(i int) model.Sample[float64] {
+		radius := operator.If(i%2 == 0, 0.5, 1.0) + (r.Float64()-0.5)*0.2
+		theta := r.Float64() * 2 * math.Pi
+		return model.Sample[float64]{
+			Attributes: tensor.Vec(radius*math.Cos(theta), radius*math.Sin(theta)),
+			Label:      operator.If(i%2 == 0, 1.0, -1.0),
+		}
+	})
+}
+
+func TestNonlinear(t *testing.T) {
+	type T = float64
+	var datasets = []struct {
+		name     string
+		generate func(int, *rand.Rand) []model.Sample[T]
+	}{
+		{"xor", xor},
+		{"circles", circles},
+	}
+	var kernels = []struct {
+		name   string
+		kernel svm.Kernel[T]
+	}{
+		{"rbf", svm.RBF[T](2)},
+		{"polynomial", svm.Polynomial[T](2, 1, 1)},
+		{"laplacian", svm.Laplacian[T](2)},
+	}
+	for _, d := range datasets {
+		var r = rand.New(rand.NewSource(1))
+		var train, test = d.generate(200, r), d.generate(200, r)
+		for _, k := range kernels {
+			var c = svm.NewClassifier[T](10, k.kernel)
+			c.Train(train, nil)
+			var accuracy = metrics.Accuracy(test, slices.Map(test, func(x model.Sample[T]) T {
+				return c.Predict(x.Attributes)
+			}))
+			t.Logf("%s/%s: accuracy %.3f", d.name, k.name, accuracy)
+			if accuracy < 0.9 {
+				t.Errorf("%s/%s: accuracy %.3f too low", d.name, k.name, accuracy)
+			}
+		}
+	}
+	// chi-squared kernel requires non-negative features
+	var r = rand.New(rand.NewSource(1))
+	var shift = func(samples []model.Sample[T]) []model.Sample[T] {
+		for i := range samples {
+			samples[i].Attributes = tensor.Vec(samples[i].Attributes[0]+1, samples[i].Attributes[1]+1)
+		}
+		return samples
+	}
+	var train, test = shift(xor(200, r)), shift(xor(200, r))
+	var c = svm.NewClassifier[T](10, svm.ChiSquared[T](2))
+	c.Train(train, nil)
+	var accuracy = metrics.Accuracy(test, slices.Map(test, func(x model.Sample[T]) T {
+		return c.Predict(x.Attributes)
+	}))
+	t.Logf("xor/chi-squared: accuracy %.3f", accuracy)
+	if accuracy < 0.9 {
+		t.Errorf("xor/chi-squared: accuracy %.3f too low", accuracy)
+	}
+}
+
+func TestCache(t *testing.T) {
+	type T = float64
+	var r = rand.New(rand.NewSource(1))
+	var samples = circles(3000, r)
+	var kernel = svm.RBF[T](1)
+	var gram = slices.Map(samples, func(x model.Sample[T]) []T {
+		return slices.Map(samples, func(y model.Sample[T]) T {
+			return kernel(x.Attributes, y.Attributes)
+		})
+	})
+	var start = time.Now()
+	var c = svm.NewClassifier[T](1, kernel)
+	c.Train(samples, nil)
+	t.Logf("cached: %d iterations in %v", c.Iterations(), time.Since(start))
+
+	var classifiers = []*svm.Classifier[T]{
+		// only 2 rows cached
+		svm.NewClassifier[T](1, kernel, svm.WithCacheSize(0)),
+		svm.NewClassifier[T](1, kernel, svm.WithGram(samples, gram)),
+	}
+	for _, c1 := range classifiers {
+		c1.Train(samples, nil)
+		if c1.Iterations() != c.Iterations() {
+			t.Fatalf("iterations: got %d, want %d", c1.Iterations(), c.Iterations())
+		}
+		for _, x := range samples[:100] {
+			if got, want := c1.Decision(x.Attributes), c.Decision(x.Attributes); got != want {
+				t.Fatalf("decision %v: got %v, want %v", x.Attributes, got, want)
+			}
+		}
+	}
+}
+
+func TestGramSubsets(t *testing.T) {
+	type T = float64
+	var r = rand.New(rand.NewSource(1))
+	var samples = blobs(300, 3, r)
+	var kernel = svm.RBF[T](0.5)
+	var gram = slices.Map(samples, func(x model.Sample[T]) []T {
+		return slices.Map(samples, func(y model.Sample[T]) T {
+			return kernel(x.Attributes, y.Attributes)
+		})
+	})
+	// every binary problem of multiclass is a subset of samples
+	var m1 = svm.NewMulticlass(svm.OneVsOne, func() *svm.Classifier[T] {
+		return svm.NewClassifier[T](1, kernel)
+	})
+	var m2 = svm.NewMulticlass(svm.OneVsOne, func() *svm.Classifier[T] {
+		return svm.NewClassifier[T](1, kernel, svm.WithGram(samples, gram))
+	})
+	m1.Train(samples, nil)
+	m2.Train(samples, nil)
+	for _, x := range samples {
+		if got, want := m2.Predict(x.Attributes), m1.Predict(x.Attributes); got != want {
+			t.Fatalf("predict %v: got %v, want %v", x.Attributes, got, want)
+		}
+	}
+	for _, c := range m2.Classifiers() {
+		if c.GramMisses() != 0 {
+			t.Fatalf("binary problem of multiclass: %d samples missed Gram matrix", c.GramMisses())
+		}
+	}
+	// samples out of Gram matrix are evaluated by kernel
+	var c1 = svm.NewClassifier[T](1, kernel)
+	var c2 = svm.NewClassifier[T](1, kernel, svm.WithGram(samples[:100], slices.Map(gram[:100], func(row []T) []T {
+		return row[:100]
+	})))
+	var mixed = blobs(50, 3, r)
+	mixed = append(mixed, samples[:100]...)
+	c1.Train(mixed, nil)
+	c2.Train(mixed, nil)
+	for _, x := range mixed {
+		if got, want := c2.Decision(x.Attributes), c1.Decision(x.Attributes); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("decision %v: got %v, want %v", x.Attributes, got, want)
+		}
+	}
+	if c2.GramMisses() != 50 {
+		t.Fatalf("mixed samples: got %d misses, want 50", c2.GramMisses())
+	}
+	// transformed samples are copies even if values unchanged
+	var identity = preprocessing.NewImputer[T](preprocessing.Mean)
+	identity.Fit(samples)
+	var c3 = svm.NewClassifier[T](1, kernel, svm.WithGram(samples, gram))
+	c3.Train(preprocessing.TransformSamples[T](identity, samples[:100]), nil)
+	if c3.GramMisses() != 100 {
+		t.Fatalf("transformed samples: got %d misses, want 100", c3.GramMisses())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("gram matrix of mismatched type should panic")
+		}
+	}()
+	svm.NewClassifier[float32](1, nil, svm.WithGram(samples, gram))
+}
+
+// blobs returns samples around k centers on a circle labeled by center
+func blobs(n, k int, r *rand.Rand) []model.Sample[float64] {
+	return slices.Map(tensor.RangeN(n), func(i int) model.Sample[float64] {
+		c := i % k
+		theta := 2 * math.Pi * float64(c) / float64(k)
+		return model.Sample[float64]{
+			Attributes: tensor.Vec(math.Cos(theta)*3+r.NormFloat64()*0.3, math.Sin(theta)*3+r.NormFloat64()*0.3),
+			Label:      float64(c),
+		}
+	})
+}
+
+func TestMulticlass(t *testing.T) {
+	type T = float64
+	const k = 5
+	var r = rand.New(rand.NewSource(1))
+	var train, test = blobs(500, k, r), blobs(500, k, r)
+	var newClassifier = func() *svm.Classifier[T] {
+		return svm.NewClassifier[T](1, svm.RBF[T](0.5))
+	}
+	for _, strategy := range []svm.Strategy{svm.OneVsRest, svm.OneVsOne} {
+		var m = svm.NewMulticlass(strategy, newClassifier)
+		m.Train(train, nil)
+		var want = operator.If(strategy == svm.OneVsOne, k*(k-1)/2, k)
+		if len(m.Classes()) != k || len(m.Classifiers()) != want {
+			t.Fatalf("%v: got %d classes and %d classifiers", strategy, len(m.Classes()), len(m.Classifiers()))
+		}
+		var accuracy = metrics.Accuracy(test, slices.Map(test, func(x model.Sample[T]) T {
+			return m.Predict(x.Attributes)
+		}))
+		t.Logf("%v: accuracy %.3f", strategy, accuracy)
+		if accuracy < 0.95 {
+			t.Fatalf("%v: accuracy %.3f too low", strategy, accuracy)
+		}
+		for _, x := range test[:k] {
+			var values = m.Decision(x.Attributes)
+			if len(values) != k {
+				t.Fatalf("%v: got %d decision values, want %d", strategy, len(values), k)
+			}
+		}
+
+		var m1, m2 = model.TestEncoding(m, func() *svm.Multiclass[T] {
+			return svm.NewMulticlass(svm.OneVsRest, newClassifier)
+		}, test, t)
+		if m1.Strategy() != strategy || m2.Strategy() != strategy {
+			t.Fatalf("strategy: got %v and %v, want %v", m1.Strategy(), m2.Strategy(), strategy)
+		}
+
+		// zero Multiclass can't create classifiers to decode into
+		data, err := m.MarshalJSON()
+		if err != nil {
+			t.Fatalf("marshal json error: %v", err)
+		}
+		if err := new(svm.Multiclass[T]).UnmarshalJSON(data); err != svm.ErrClassifierNotDecodable {
+			t.Fatalf("unmarshal json: got error %v, want %v", err, svm.ErrClassifierNotDecodable)
+		}
+		data, err = m.MarshalBinary()
+		if err != nil {
+			t.Fatalf("marshal binary error: %v", err)
+		}
+		if err := new(svm.Multiclass[T]).UnmarshalBinary(data); err != svm.ErrClassifierNotDecodable {
+			t.Fatalf("unmarshal binary: got error %v, want %v", err, svm.ErrClassifierNotDecodable)
+		}
+	}
 }
@@ -0,0 +1,178 @@
+package svm
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/model"
+)
+
+// classifierState is the encoded form of Classifier
+type classifierState[T constraints.Float] struct {
+	Alphas         tensor.Vector[T]  `json:"alphas"`
+	SupportVectors []model.Sample[T] `json:"support_vectors"`
+	Bias           T                 `json:"bias"`
+	C              T                 `json:"c"`
+	Min            tensor.Vector[T]  `json:"min,omitempty"`
+	Max            tensor.Vector[T]  `json:"max,omitempty"`
+}
+
+func (c *Classifier[T]) state() classifierState[T] {
+	return classifierState[T]{
+		Alphas:         c.a,
+		SupportVectors: c.s,
+		Bias:           c.b,
+		C:              c.c,
+		Min:            c.min,
+		Max:            c.max,
+	}
+}
+
+func (c *Classifier[T]) setState(s classifierState[T]) {
+	c.a = s.Alphas
+	c.s = s.SupportVectors
+	c.b = s.Bias
+	c.c = s.C
+	c.min = s.Min
+	c.max = s.Max
+	c.updateWeights()
+}
+
+// MarshalJSON implements json.Marshaler. Kernel is a function and can not be
+// encoded, the decoding classifier should be created with the same kernel.
+func (c *Classifier[T]) MarshalJSON() ([]byte, error) {
+	return model.EncodeJSON(c.state())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (c *Classifier[T]) UnmarshalJSON(data []byte) error {
+	var s classifierState[T]
+	if err := model.DecodeJSON(data, &s); err != nil {
+		return err
+	}
+	c.setState(s)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (c *Classifier[T]) MarshalBinary() ([]byte, error) {
+	return model.EncodeBinary(c.state())
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (c *Classifier[T]) UnmarshalBinary(data []byte) error {
+	var s classifierState[T]
+	if err := model.DecodeBinary(data, &s); err != nil {
+		return err
+	}
+	c.setState(s)
+	return nil
+}
+
+// ErrClassifierNotDecodable is returned when decoding a Multiclass which has
+// no newClassifier to create binary classifiers
+var ErrClassifierNotDecodable = errors.New("svm: classifier not decodable")
+
+// Binary classifiers of Multiclass are encoded by themselves and decoded
+// into classifiers created by newClassifier, which carries the kernel.
+
+func (m *Multiclass[T]) newClassifiers(n int) ([]*Classifier[T], error) {
+	if m.newClassifier == nil {
+		return nil, ErrClassifierNotDecodable
+	}
+	var classifiers = make([]*Classifier[T], n)
+	for i := range classifiers {
+		classifiers[i] = m.newClassifier()
+	}
+	return classifiers, nil
+}
+
+type multiclassJSONState[T constraints.Float] struct {
+	Strategy    Strategy          `json:"strategy"`
+	Classes     []T               `json:"classes"`
+	Classifiers []json.RawMessage `json:"classifiers"`
+}
+
+type multiclassBinaryState[T constraints.Float] struct {
+	Strategy    Strategy
+	Classes     []T
+	Classifiers [][]byte
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *Multiclass[T]) MarshalJSON() ([]byte, error) {
+	var s = multiclassJSONState[T]{
+		Strategy:    m.strategy,
+		Classes:     m.classes,
+		Classifiers: make([]json.RawMessage, len(m.classifiers)),
+	}
+	for i, c := range m.classifiers {
+		data, err := c.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		s.Classifiers[i] = data
+	}
+	return model.EncodeJSON(s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (m *Multiclass[T]) UnmarshalJSON(data []byte) error {
+	var s multiclassJSONState[T]
+	if err := model.DecodeJSON(data, &s); err != nil {
+		return err
+	}
+	classifiers, err := m.newClassifiers(len(s.Classifiers))
+	if err != nil {
+		return err
+	}
+	for i := range classifiers {
+		if err := classifiers[i].UnmarshalJSON(s.Classifiers[i]); err != nil {
+			return err
+		}
+	}
+	m.strategy = s.Strategy
+	m.classes = s.Classes
+	m.classifiers = classifiers
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (m *Multiclass[T]) MarshalBinary() ([]byte, error) {
+	var s = multiclassBinaryState[T]{
+		Strategy:    m.strategy,
+		Classes:     m.classes,
+		Classifiers: make([][]byte, len(m.classifiers)),
+	}
+	for i, c := range m.classifiers {
+		data, err := c.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		s.Classifiers[i] = data
+	}
+	return model.EncodeBinary(s)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (m *Multiclass[T]) UnmarshalBinary(data []byte) error {
+	var s multiclassBinaryState[T]
+	if err := model.DecodeBinary(data, &s); err != nil {
+		return err
+	}
+	classifiers, err := m.newClassifiers(len(s.Classifiers))
+	if err != nil {
+		return err
+	}
+	for i := range classifiers {
+		if err := classifiers[i].UnmarshalBinary(s.Classifiers[i]); err != nil {
+			return err
+		}
+	}
+	m.strategy = s.Strategy
+	m.classes = s.Classes
+	m.classifiers = classifiers
+	return nil
+}
@@ -0,0 +1,44 @@
+package svm
+
+import (
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/ml/model"
+)
+
+// gram is a precomputed Gram matrix indexed by identity of samples, i.e.
+// address of underlying array of attributes, so that it's also available
+// to subsets of the samples, e.g. folds of cross validation.
+type gram[T constraints.Float] struct {
+	indices map[*T]int
+	values  [][]T
+}
+
+func newGram[T constraints.Float](samples []model.Sample[T], values [][]T) *gram[T] {
+	if len(values) != len(samples) {
+		panic("svm: size of gram matrix mismatched")
+	}
+	var g = &gram[T]{
+		indices: make(map[*T]int, len(samples)),
+		values:  values,
+	}
+	for i := range samples {
+		if len(values[i]) != len(samples) {
+			panic("svm: gram matrix is not square")
+		}
+		if len(samples[i].Attributes) > 0 {
+			g.indices[&samples[i].Attributes[0]] = i
+		}
+	}
+	return g
+}
+
+// index returns index of sample in the Gram matrix, or -1 if not found
+func (g *gram[T]) index(sample model.Sample[T]) int {
+	if len(sample.Attributes) == 0 {
+		return -1
+	}
+	if i, ok := g.indices[&sample.Attributes[0]]; ok {
+		return i
+	}
+	return -1
+}
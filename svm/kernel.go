@@ -0,0 +1,78 @@
+package svm
+
+import (
+	"math"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/mathutil"
+	"github.com/gopherd/doge/math/tensor"
+)
+
+// Kernel computes inner product of two vectors in feature space: k(x,y) = φ(x)‧φ(y)
+type Kernel[T constraints.Float] func(tensor.Vector[T], tensor.Vector[T]) T
+
+// Linear returns kernel k(x,y) = x‧y, it's the default kernel of Classifier
+func Linear[T constraints.Float]() Kernel[T] {
+	return func(x, y tensor.Vector[T]) T {
+		return x.Dot(y)
+	}
+}
+
+// RBF returns gaussian radial basis function kernel k(x,y) = exp(-γ‖x-y‖²)
+func RBF[T constraints.Float](gamma T) Kernel[T] {
+	return func(x, y tensor.Vector[T]) T {
+		var squared T
+		for i := range x {
+			var d = x[i] - y[i]
+			squared += d * d
+		}
+		return T(math.Exp(float64(-gamma * squared)))
+	}
+}
+
+// Polynomial returns polynomial kernel k(x,y) = (γ‧x‧y + coef0)ᵈ
+func Polynomial[T constraints.Float](degree int, gamma, coef0 T) Kernel[T] {
+	return func(x, y tensor.Vector[T]) T {
+		var base = gamma*x.Dot(y) + coef0
+		var result T = 1
+		for i := 0; i < degree; i++ {
+			result *= base
+		}
+		return result
+	}
+}
+
+// Sigmoid returns sigmoid kernel k(x,y) = tanh(γ‧x‧y + coef0), it's not
+// positive semi-definite for all parameters
+func Sigmoid[T constraints.Float](gamma, coef0 T) Kernel[T] {
+	return func(x, y tensor.Vector[T]) T {
+		return T(math.Tanh(float64(gamma*x.Dot(y) + coef0)))
+	}
+}
+
+// Laplacian returns laplacian kernel k(x,y) = exp(-γ‖x-y‖₁)
+func Laplacian[T constraints.Float](gamma T) Kernel[T] {
+	return func(x, y tensor.Vector[T]) T {
+		var sum T
+		for i := range x {
+			sum += mathutil.Abs(x[i] - y[i])
+		}
+		return T(math.Exp(float64(-gamma * sum)))
+	}
+}
+
+// ChiSquared returns exponential chi-squared kernel
+// k(x,y) = exp(-γ‧Σᵢ((xᵢ-yᵢ)²/(xᵢ+yᵢ))), it's designed for non-negative
+// features such as histograms, and terms with xᵢ+yᵢ=0 are ignored
+func ChiSquared[T constraints.Float](gamma T) Kernel[T] {
+	return func(x, y tensor.Vector[T]) T {
+		var sum T
+		for i := range x {
+			if s := x[i] + y[i]; s != 0 {
+				var d = x[i] - y[i]
+				sum += d * d / s
+			}
+		}
+		return T(math.Exp(float64(-gamma * sum)))
+	}
+}
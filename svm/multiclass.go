@@ -0,0 +1,234 @@
+package svm
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/gopherd/doge/constraints"
+	"github.com/gopherd/doge/math/mathutil"
+	"github.com/gopherd/doge/math/tensor"
+	"github.com/gopherd/ml/model"
+)
+
+// Strategy represents how multiclass problem is reduced to binary problems
+type Strategy int
+
+const (
+	// OneVsRest trains k classifiers, each separates a class from the others
+	OneVsRest Strategy = iota
+	// OneVsOne trains k(k-1)/2 classifiers, one for each pair of classes,
+	// and predicts by voting
+	OneVsOne
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case OneVsRest:
+		return "one-vs-rest"
+	case OneVsOne:
+		return "one-vs-one"
+	default:
+		return "unknown"
+	}
+}
+
+type multiclassOptions struct {
+	concurrency int
+}
+
+func defaultMulticlassOptions() multiclassOptions {
+	return multiclassOptions{
+		concurrency: runtime.NumCPU(),
+	}
+}
+
+// MulticlassOption represents an option of Multiclass
+type MulticlassOption func(opt *multiclassOptions)
+
+func (opt *multiclassOptions) apply(options []MulticlassOption) {
+	for _, o := range options {
+		o(opt)
+	}
+}
+
+// WithConcurrency sets maximum number of binary classifiers trained
+// concurrently, default is number of CPUs
+func WithConcurrency(n int) MulticlassOption {
+	return func(opt *multiclassOptions) {
+		opt.concurrency = n
+	}
+}
+
+var _ model.Model[float64] = (*Multiclass[float64])(nil)
+
+// Multiclass is a multiclass classifier composed of binary classifiers,
+// labels are arbitrary values instead of -1 and +1
+type Multiclass[T constraints.Float] struct {
+	strategy      Strategy
+	newClassifier func() *Classifier[T]
+	options       multiclassOptions
+
+	classes     []T
+	classifiers []*Classifier[T]
+}
+
+// NewMulticlass creates a multiclass classifier whose binary classifiers
+// are created by newClassifier, e.g.
+//
+//	svm.NewMulticlass(svm.OneVsOne, func() *svm.Classifier[T] {
+//		return svm.NewClassifier(1, svm.RBF[T](0.5))
+//	})
+func NewMulticlass[T constraints.Float](strategy Strategy, newClassifier func() *Classifier[T], options ...MulticlassOption) *Multiclass[T] {
+	var m = &Multiclass[T]{
+		strategy:      strategy,
+		newClassifier: newClassifier,
+		options:       defaultMulticlassOptions(),
+	}
+	m.options.apply(options)
+	if m.options.concurrency < 1 {
+		m.options.concurrency = 1
+	}
+	return m
+}
+
+// Strategy returns strategy of the classifier
+func (m *Multiclass[T]) Strategy() Strategy {
+	return m.strategy
+}
+
+// Classes returns sorted classes seen in training
+func (m *Multiclass[T]) Classes() []T {
+	return m.classes
+}
+
+// Classifiers returns trained binary classifiers. For OneVsRest, i-th
+// classifier separates i-th class (+1) from the others (-1). For OneVsOne,
+// classifiers are ordered by pairs (0,1),(0,2),...,(1,2),..., classifier of
+// pair (i,j) separates i-th class (+1) from j-th class (-1).
+func (m *Multiclass[T]) Classifiers() []*Classifier[T] {
+	return m.classifiers
+}
+
+// pairs calls f for each pair of classes in order of classifiers
+func (m *Multiclass[T]) pairs(f func(k, i, j int)) {
+	var k int
+	for i := range m.classes {
+		for j := i + 1; j < len(m.classes); j++ {
+			f(k, i, j)
+			k++
+		}
+	}
+}
+
+// Train trains binary classifiers concurrently
+func (m *Multiclass[T]) Train(samples []model.Sample[T], tracker model.Tracker) {
+	var counters = model.WeightedCounters(samples)
+	m.classes = make([]T, 0, len(counters))
+	for class := range counters {
+		m.classes = append(m.classes, class)
+	}
+	sort.Slice(m.classes, func(i, j int) bool {
+		return m.classes[i] < m.classes[j]
+	})
+
+	var subsets [][]model.Sample[T]
+	switch m.strategy {
+	case OneVsOne:
+		var indices = make(map[T]int, len(m.classes))
+		for i, class := range m.classes {
+			indices[class] = i
+		}
+		var groups = make([][]model.Sample[T], len(m.classes))
+		for _, x := range samples {
+			i := indices[x.Label]
+			groups[i] = append(groups[i], x)
+		}
+		subsets = make([][]model.Sample[T], len(m.classes)*(len(m.classes)-1)/2)
+		m.pairs(func(k, i, j int) {
+			var subset = make([]model.Sample[T], 0, len(groups[i])+len(groups[j]))
+			subset = append(subset, groups[i]...)
+			subset = append(subset, groups[j]...)
+			for t := range subset {
+				subset[t].Label = mathutil.Predict[T](t < len(groups[i]))*2 - 1
+			}
+			subsets[k] = subset
+		})
+	default:
+		subsets = make([][]model.Sample[T], len(m.classes))
+		for i, class := range m.classes {
+			var subset = make([]model.Sample[T], len(samples))
+			copy(subset, samples)
+			for t := range subset {
+				subset[t].Label = mathutil.Predict[T](subset[t].Label == class)*2 - 1
+			}
+			subsets[i] = subset
+		}
+	}
+
+	m.classifiers = make([]*Classifier[T], len(subsets))
+	var sem = make(chan struct{}, m.options.concurrency)
+	var wg sync.WaitGroup
+	for i := range subsets {
+		var c = m.newClassifier()
+		var subset = subsets[i]
+		m.classifiers[i] = c
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.Train(subset, nil)
+		}()
+	}
+	wg.Wait()
+}
+
+// Decision returns decision values of classes, the predicted class has the
+// largest value. For OneVsRest, it's the decision value of each binary
+// classifier. For OneVsOne, it's number of votes plus sum of decision values
+// of the class scaled into (-⅓,⅓), so that ties of votes are broken by
+// confidence.
+func (m *Multiclass[T]) Decision(x tensor.Vector[T]) tensor.Vector[T] {
+	var values = make(tensor.Vector[T], len(m.classes))
+	if m.strategy != OneVsOne {
+		for i, c := range m.classifiers {
+			values[i] = c.Decision(x)
+		}
+		return values
+	}
+	var votes = make(tensor.Vector[T], len(m.classes))
+	m.pairs(func(k, i, j int) {
+		var d = m.classifiers[k].Decision(x)
+		if d > 0 {
+			votes[i]++
+		} else {
+			votes[j]++
+		}
+		values[i] += d
+		values[j] -= d
+	})
+	for i := range values {
+		values[i] = votes[i] + values[i]/(3*(mathutil.Abs(values[i])+1))
+	}
+	return values
+}
+
+func (m *Multiclass[T]) Predict(x tensor.Vector[T]) T {
+	if len(m.classes) == 0 {
+		return 0
+	}
+	if len(m.classes) == 1 {
+		return m.classes[0]
+	}
+	var values = m.Decision(x)
+	var best int
+	for i := range values {
+		if values[i] > values[best] {
+			best = i
+		}
+	}
+	return m.classes[best]
+}
@@ -1,4 +1,8 @@
 // 西瓜样本数据属性定义
+//
+// v2: 西瓜数据集 2.0, 属性: color,root,sound,texture,navel,touch
+// v3: 西瓜数据集 3.0, 在 2.0 基础上增加连续属性 density(密度), sugar(含糖率)
+// raw: 未编码的西瓜数据集 3.0 原始数据, 首列为编号, 可由 dataloader.LoadCSVEncoded 直接加载
 package watermelon
 
 // colors